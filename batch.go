@@ -0,0 +1,186 @@
+package edgetts
+
+import (
+	"context"
+	"iter"
+	"sync"
+	"time"
+)
+
+// BatchJob describes one speech generation in batch
+type BatchJob struct {
+	// Text to speak
+	Text string
+
+	// Parameters of speech generation. Empty fields are taken from EdgeTTS args
+	Args Args
+
+	// Format of sound data. Use one of OutputFormat* constants
+	Format OutputFormat
+
+	// File to save sound to. If empty, sound is returned in BatchResult.Audio
+	Filename string
+}
+
+// BatchResult contains result of one batch job
+type BatchResult struct {
+	// Index of job in jobs sequence
+	Index int
+
+	// Job itself
+	Job BatchJob
+
+	// Sound data if job has no Filename
+	Audio []byte
+
+	// Timings of each word in text
+	Metadata []SpeechMetadata
+
+	// Error if generation failed
+	Err error
+}
+
+// BatchOptions contains parameters of batch processing
+type BatchOptions struct {
+	// Maximum count of simultaneous generations, default is 4
+	Concurrency int
+
+	// Timeout of each job, zero means no timeout
+	Timeout time.Duration
+
+	// Stop processing on first failed job. Otherwise failed jobs are reported and processing continues
+	FailFast bool
+}
+
+// defaultBatchConcurrency is count of simultaneous generations if BatchOptions.Concurrency not set
+const defaultBatchConcurrency = 4
+
+// indexedJob is job with its index in jobs sequence
+type indexedJob struct {
+	index int
+	job   BatchJob
+}
+
+// Batch generates speech for many texts with bounded concurrency.
+// Results are yielded in order of completion, use BatchResult.Index to match them with jobs.
+// When ctx is cancelled, jobs in progress are reported with context error and no more jobs are taken.
+// Breaking the loop over results cancels jobs in progress.
+//
+// Parameters:
+//
+//	ctx - context to stop all jobs before they finished
+//	jobs - sequence of jobs, use slices.Values() to process slice. It is consumed in separate goroutine
+//	  which stops it as soon as processing is stopped, sequence blocked in waiting for next job
+//	  is stopped when it yields one
+//	options - concurrency, per-job timeout and error handling mode
+//
+// Returns:
+//
+//	iterator of job results
+func (etts *EdgeTTS) Batch(ctx context.Context, jobs iter.Seq[BatchJob], options BatchOptions) iter.Seq[BatchResult] {
+	return func(yield func(BatchResult) bool) {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		concurrency := options.Concurrency
+		if concurrency <= 0 {
+			concurrency = defaultBatchConcurrency
+		}
+
+		queue := make(chan indexedJob)
+		results := make(chan BatchResult)
+
+		// feed jobs to workers until sequence ends or processing is stopped
+		go func() {
+			defer close(queue)
+
+			next, stop := iter.Pull(jobs)
+			defer stop()
+
+			for index := 0; ctx.Err() == nil; index++ {
+				job, ok := next()
+				if !ok {
+					return
+				}
+
+				select {
+				case queue <- indexedJob{index: index, job: job}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+
+		var wg sync.WaitGroup
+		for range concurrency {
+			wg.Go(func() {
+				for {
+					// don't wait for feeder which may be blocked in sequence after processing is stopped
+					var item indexedJob
+					var ok bool
+
+					select {
+					case item, ok = <-queue:
+						if !ok {
+							return
+						}
+					case <-ctx.Done():
+						return
+					}
+
+					if err := ctx.Err(); err != nil {
+						results <- BatchResult{Index: item.index, Job: item.job, Err: err}
+						continue
+					}
+
+					results <- etts.runBatchJob(ctx, item, options.Timeout)
+				}
+			})
+		}
+
+		go func() {
+			wg.Wait()
+			close(results)
+		}()
+
+		for result := range results {
+			if !yield(result) || (result.Err != nil && options.FailFast) {
+				cancel()
+				break
+			}
+		}
+
+		// wait for workers to stop, results of cancelled jobs are dropped
+		for range results {
+		}
+	}
+}
+
+func (etts *EdgeTTS) runBatchJob(ctx context.Context, item indexedJob, timeout time.Duration) BatchResult {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	job := item.job
+	result := BatchResult{
+		Index: item.index,
+		Job:   job,
+	}
+
+	speaker := etts.Speak(job.Text)
+	speaker.args = mergeArgs(job.Args, etts.args)
+
+	if job.Filename != "" {
+		result.Err = speaker.SaveToFile(ctx, job.Filename, job.Format)
+	} else {
+		result.Audio, result.Err = speaker.GetSound(ctx, job.Format)
+	}
+
+	if result.Err == nil {
+		result.Metadata, result.Err = speaker.GetMetadata()
+	}
+
+	return result
+}
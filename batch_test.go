@@ -0,0 +1,207 @@
+package edgetts
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+func Test_Batch(t *testing.T) {
+	texts := []BatchJob{
+		{Text: "one", Format: OutputFormatRaw24000},
+		{Text: "two", Format: OutputFormatRaw24000},
+		{Text: "three", Format: OutputFormatRaw24000},
+		{Text: "four", Format: OutputFormatRaw24000},
+		{Text: "five", Format: OutputFormatRaw24000},
+	}
+
+	errAny := errors.New("any error")
+
+	tests := []struct {
+		name    string
+		jobs    []BatchJob
+		options BatchOptions
+		cancel  bool
+
+		// called with spoken text before server response
+		respond func(text string) bool
+
+		// expected errors of yielded results by job index, errAny matches any error
+		want map[int]error
+	}{
+		{
+			name:    "all jobs",
+			jobs:    texts,
+			options: BatchOptions{Concurrency: 2},
+			want:    map[int]error{0: nil, 1: nil, 2: nil, 3: nil, 4: nil},
+		},
+		{
+			name: "job args merged with defaults",
+			jobs: []BatchJob{
+				{Text: "one", Args: Args{Rate: "+10%"}, Format: OutputFormatRaw24000},
+				{Text: "two", Args: Args{Voice: "wrong"}, Format: OutputFormatRaw24000},
+			},
+			want: map[int]error{0: nil, 1: errAny},
+		},
+		{
+			name:    "per-job timeout",
+			jobs:    texts[:3],
+			options: BatchOptions{Concurrency: 3, Timeout: 100 * time.Millisecond},
+			respond: func(text string) bool {
+				if text == "two" {
+					time.Sleep(300 * time.Millisecond)
+				}
+				return true
+			},
+			want: map[int]error{0: nil, 1: context.DeadlineExceeded, 2: nil},
+		},
+		{
+			name: "fail fast",
+			jobs: append(
+				[]BatchJob{{Text: "zero", Args: Args{Voice: "wrong"}, Format: OutputFormatRaw24000}},
+				texts...,
+			),
+			options: BatchOptions{Concurrency: 1, FailFast: true},
+			want:    map[int]error{0: errAny},
+		},
+		{
+			name:    "continue on error",
+			jobs:    append([]BatchJob{{Text: "zero", Args: Args{Voice: "wrong"}}}, texts[:2]...),
+			options: BatchOptions{Concurrency: 1},
+			want:    map[int]error{0: errAny, 1: nil, 2: nil},
+		},
+		{
+			name:    "cancelled",
+			jobs:    texts,
+			options: BatchOptions{Concurrency: 2},
+			cancel:  true,
+			want:    map[int]error{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newEdgeServer(t)
+			server.setRespond(tt.respond)
+
+			ctx, cancel := context.WithCancel(t.Context())
+			defer cancel()
+
+			if tt.cancel {
+				cancel()
+			}
+
+			var results []BatchResult
+			for result := range newTestEdgeTTS(server).Batch(ctx, slices.Values(tt.jobs), tt.options) {
+				results = append(results, result)
+			}
+
+			if len(results) != len(tt.want) {
+				t.Fatalf("expected %d results, but got %d: '%+v'", len(tt.want), len(results), results)
+			}
+
+			slices.SortFunc(results, func(a, b BatchResult) int {
+				return a.Index - b.Index
+			})
+
+			for _, result := range results {
+				want, ok := tt.want[result.Index]
+				if !ok {
+					t.Errorf("unexpected result of job %d", result.Index)
+					continue
+				}
+
+				if result.Job != tt.jobs[result.Index] {
+					t.Errorf("expected job %d to be '%+v', but got '%+v'", result.Index, tt.jobs[result.Index], result.Job)
+				}
+
+				switch {
+				case want == errAny:
+					if result.Err == nil {
+						t.Errorf("expected job %d to fail", result.Index)
+					}
+				case !errors.Is(result.Err, want):
+					t.Errorf("expected error of job %d to be '%v', but got '%v'", result.Index, want, result.Err)
+				case want == nil && string(result.Audio) != result.Job.Text:
+					t.Errorf("expected sound of job %d to be '%s', but got '%s'", result.Index, result.Job.Text, result.Audio)
+				}
+			}
+		})
+	}
+}
+
+func Test_BatchBlockedJobs(t *testing.T) {
+	tests := []struct {
+		name    string
+		job     BatchJob
+		options BatchOptions
+
+		// stop processing after the first result
+		stop func(cancel context.CancelFunc) bool
+	}{
+		{
+			name:    "fail fast",
+			job:     BatchJob{Text: "one", Args: Args{Voice: "wrong"}, Format: OutputFormatRaw24000},
+			options: BatchOptions{FailFast: true},
+		},
+		{
+			name: "break",
+			job:  BatchJob{Text: "one", Format: OutputFormatRaw24000},
+			stop: func(cancel context.CancelFunc) bool { return true },
+		},
+		{
+			name: "cancelled",
+			job:  BatchJob{Text: "one", Format: OutputFormatRaw24000},
+			stop: func(cancel context.CancelFunc) bool {
+				cancel()
+				return false
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newEdgeServer(t)
+
+			ctx, cancel := context.WithCancel(t.Context())
+			defer cancel()
+
+			// sequence of jobs which blocks after the first job, like channel nobody closes
+			pending := make(chan BatchJob, 1)
+			pending <- tt.job
+			defer close(pending)
+
+			jobs := func(yield func(BatchJob) bool) {
+				for job := range pending {
+					if !yield(job) {
+						return
+					}
+				}
+			}
+
+			done := make(chan int)
+			go func() {
+				count := 0
+				for range newTestEdgeTTS(server).Batch(ctx, jobs, tt.options) {
+					count++
+					if tt.stop != nil && tt.stop(cancel) {
+						break
+					}
+				}
+
+				done <- count
+			}()
+
+			select {
+			case count := <-done:
+				if count != 1 {
+					t.Errorf("expected 1 result, but got %d", count)
+				}
+			case <-time.After(time.Second):
+				t.Fatal("expected batch to stop while sequence of jobs is blocked")
+			}
+		})
+	}
+}
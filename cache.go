@@ -0,0 +1,186 @@
+package edgetts
+
+import (
+	"bytes"
+	"context"
+	"iter"
+
+	"github.com/kolonist/edgetts/internal/cache"
+	"github.com/kolonist/edgetts/internal/tts"
+)
+
+// cacheReplayChunkSize is size of sound data pieces yielded on cache hit
+const cacheReplayChunkSize = 4096
+
+// Cache stores synthesized sound and metadata by hash of text, voice, rate, volume, pitch, format and SSML.
+// Implement it to use your own storage, implementations should be safe for concurrent use
+type Cache = cache.Cache
+
+// CacheEntry is synthesized sound of one request to Edge TTS server
+type CacheEntry = cache.Entry
+
+// CacheOptions contains cache size limits and eviction settings
+type CacheOptions = cache.Options
+
+// NewMemoryCache creates in-memory cache which evicts least recently used entries.
+//
+// Parameters:
+//
+//	options - maximum size in bytes, maximum count of entries and entry time to live
+//
+// Returns:
+//
+//	cache to pass to WithCache()
+func NewMemoryCache(options CacheOptions) Cache {
+	return cache.NewMemory(options)
+}
+
+// NewFileCache creates filesystem cache which evicts least recently used entries.
+//
+// Parameters:
+//
+//	dir - directory to store cache files in, created if it does not exist
+//	options - maximum size in bytes, maximum count of entries and entry time to live
+//
+// Returns:
+//
+//	cache to pass to WithCache()
+//	error if directory can't be created
+func NewFileCache(dir string, options CacheOptions) (Cache, error) {
+	return cache.NewFile(dir, options)
+}
+
+// WithCache stores synthesized sound in cache and replays it on next synthesis with the same parameters.
+// Cache hit is yielded through GetSoundIter() and GetMetadata() exactly like live response.
+//
+// Parameters:
+//
+//	c - cache, e.g. NewMemoryCache() or NewFileCache()
+//
+// Returns:
+//
+//	option to pass to New()
+func WithCache(c Cache) Option {
+	return func(etts *EdgeTTS) {
+		etts.cache = c
+	}
+}
+
+// synthesize runs one synthesis turn using cache and request coalescing if they are enabled
+func (etts *EdgeTTS) synthesize(ctx context.Context, text string, args Args, format OutputFormat) iter.Seq2[tts.ResponseChunk, error] {
+	if etts.cache == nil && etts.flights == nil {
+		return etts.upstream(ctx, text, args, format)
+	}
+
+	key, err := tts.CacheKey(text, args, format)
+	if err != nil {
+		// let synthesis report wrong parameters
+		return etts.upstream(ctx, text, args, format)
+	}
+
+	if etts.cache != nil {
+		if entry, ok := etts.cache.Get(key); ok {
+			return replayCacheEntry(ctx, entry)
+		}
+	}
+
+	upstream := func(ctx context.Context) iter.Seq2[tts.ResponseChunk, error] {
+		return etts.cacheResponse(key, etts.upstream(ctx, text, args, format))
+	}
+
+	if etts.flights != nil {
+		return etts.flights.Do(ctx, key, upstream)
+	}
+
+	return upstream(ctx)
+}
+
+// cacheResponse stores complete response in cache while passing it through
+func (etts *EdgeTTS) cacheResponse(key string, response iter.Seq2[tts.ResponseChunk, error]) iter.Seq2[tts.ResponseChunk, error] {
+	if etts.cache == nil {
+		return response
+	}
+
+	return func(yield func(tts.ResponseChunk, error) bool) {
+		entry := &CacheEntry{}
+
+		for chunk, err := range response {
+			if err != nil {
+				yield(chunk, err)
+				return
+			}
+
+			switch chunk.ChunkType {
+			case tts.ChunkTypeAudio:
+				entry.Audio = append(entry.Audio, chunk.Data...)
+			case tts.ChunkTypeWordBoundary:
+				entry.Metadata = append(entry.Metadata, chunk.Metadata)
+				entry.AudioOffsets = append(entry.AudioOffsets, len(entry.Audio))
+			case tts.ChunkTypeEnd:
+				// store only complete response
+				etts.cache.Put(key, entry)
+			}
+
+			if !yield(chunk, nil) {
+				return
+			}
+		}
+	}
+}
+
+// replayCacheEntry yields cached sound and metadata as response chunks in order they were received.
+// Sound data is copied, so consumers can't modify cached entry
+func replayCacheEntry(ctx context.Context, entry *CacheEntry) iter.Seq2[tts.ResponseChunk, error] {
+	return func(yield func(tts.ResponseChunk, error) bool) {
+		if err := ctx.Err(); err != nil {
+			yield(tts.ResponseChunk{}, err)
+			return
+		}
+
+		// size of already yielded sound data
+		sent := 0
+
+		// yields sound data up to position
+		yieldAudio := func(until int) bool {
+			until = min(max(until, sent), len(entry.Audio))
+
+			for sent < until {
+				n := min(cacheReplayChunkSize, until-sent)
+
+				chunk := tts.ResponseChunk{
+					ChunkType: tts.ChunkTypeAudio,
+					Data:      bytes.Clone(entry.Audio[sent : sent+n]),
+				}
+
+				if !yield(chunk, nil) {
+					return false
+				}
+
+				sent += n
+			}
+
+			return true
+		}
+
+		for i, metadata := range entry.Metadata {
+			if i < len(entry.AudioOffsets) && !yieldAudio(entry.AudioOffsets[i]) {
+				return
+			}
+
+			chunk := tts.ResponseChunk{
+				ChunkType: tts.ChunkTypeWordBoundary,
+				Metadata:  metadata,
+			}
+
+			if !yield(chunk, nil) {
+				return
+			}
+		}
+
+		if !yieldAudio(len(entry.Audio)) {
+			return
+		}
+
+		yield(tts.ResponseChunk{ChunkType: tts.ChunkTypeEnd}, nil)
+	}
+}
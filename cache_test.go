@@ -0,0 +1,99 @@
+package edgetts
+
+import (
+	"context"
+	"iter"
+	"slices"
+	"testing"
+
+	"github.com/kolonist/edgetts/internal/tts"
+)
+
+// describeChunks returns short description of response chunks like "word:Hello", "audio:data" and "end"
+func describeChunks(t *testing.T, response iter.Seq2[tts.ResponseChunk, error]) []string {
+	t.Helper()
+
+	var result []string
+	for chunk, err := range response {
+		if err != nil {
+			t.Fatalf("expected error to be 'nil', but got '%v'", err)
+		}
+
+		switch chunk.ChunkType {
+		case tts.ChunkTypeAudio:
+			result = append(result, "audio:"+string(chunk.Data))
+		case tts.ChunkTypeWordBoundary:
+			result = append(result, "word:"+chunk.Metadata.Text)
+		case tts.ChunkTypeEnd:
+			result = append(result, "end")
+		}
+	}
+
+	return result
+}
+
+func Test_replayCacheEntry(t *testing.T) {
+	metadata := []SpeechMetadata{{Text: "Hello"}, {Text: "world"}}
+
+	tests := []struct {
+		name  string
+		entry CacheEntry
+		want  []string
+	}{
+		{
+			name:  "recorded order",
+			entry: CacheEntry{Audio: []byte("HelloWorld"), Metadata: metadata, AudioOffsets: []int{0, 5}},
+			want:  []string{"word:Hello", "audio:Hello", "word:world", "audio:World", "end"},
+		},
+		{
+			name:  "metadata after sound",
+			entry: CacheEntry{Audio: []byte("HelloWorld"), Metadata: metadata, AudioOffsets: []int{10, 10}},
+			want:  []string{"audio:HelloWorld", "word:Hello", "word:world", "end"},
+		},
+		{
+			name:  "entry without audio offsets",
+			entry: CacheEntry{Audio: []byte("HelloWorld"), Metadata: metadata},
+			want:  []string{"word:Hello", "word:world", "audio:HelloWorld", "end"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := describeChunks(t, replayCacheEntry(context.Background(), &tt.entry)); !slices.Equal(got, tt.want) {
+				t.Errorf("expected chunks to be '%q', but got '%q'", tt.want, got)
+			}
+		})
+	}
+}
+
+func Test_replayCacheEntryCopiesAudio(t *testing.T) {
+	entry := &CacheEntry{Audio: []byte("Hello")}
+
+	for chunk := range replayCacheEntry(context.Background(), entry) {
+		for i := range chunk.Data {
+			chunk.Data[i] = 0
+		}
+	}
+
+	if string(entry.Audio) != "Hello" {
+		t.Errorf("expected cached sound not to be modified, but got '%q'", entry.Audio)
+	}
+}
+
+func Test_WithCache(t *testing.T) {
+	server := newEdgeServer(t)
+	etts := newTestEdgeTTS(server, WithCache(NewMemoryCache(CacheOptions{})))
+
+	args := Args{Voice: testVoice}
+
+	live := describeChunks(t, etts.synthesize(t.Context(), "Hello world", args, OutputFormatRaw24000))
+	cached := describeChunks(t, etts.synthesize(t.Context(), "Hello world", args, OutputFormatRaw24000))
+
+	if !slices.Equal(live, cached) {
+		t.Errorf("expected cached response '%q' to be the same as live one '%q'", cached, live)
+	}
+
+	if _, _, texts := server.stats(); len(texts) != 1 {
+		t.Errorf("expected one request to server, but got %d", len(texts))
+	}
+}
@@ -0,0 +1,199 @@
+package edgetts
+
+import (
+	"context"
+	"fmt"
+	"iter"
+
+	"github.com/kolonist/edgetts/internal/tts"
+)
+
+// chunkBufferSize is count of response chunks buffered for each text chunk synthesized in parallel
+const chunkBufferSize = 64
+
+// chunkResult is response chunk or error passed from parallel synthesis to consumer
+type chunkResult struct {
+	chunk tts.ResponseChunk
+	err   error
+}
+
+// WithConcurrency sets count of text chunks synthesized simultaneously. Text split into chunks with WithChunkSize()
+// is synthesized one after another by default. With concurrency > 1 next chunks are prepared while previous
+// are played, but sound is still yielded strictly in order. If chunk size is not set, text is split
+// into chunks of 4096 bytes. Webm and ogg formats can't be split, see WithChunkSize().
+//
+// Parameters:
+//
+//	n - count of text chunks synthesized simultaneously, also limits count of chunks buffered in memory
+//
+// Returns:
+//
+//	the same speaker to chain calls
+func (s *Speaker) WithConcurrency(n int) *Speaker {
+	s.concurrency = n
+	return s
+}
+
+// WithChunkSize makes speaker split long text into chunks sent to Edge TTS server in separate requests.
+// Text is split on line breaks, sentence ends or spaces. By default whole text is sent in one request
+// unless WithConcurrency() is set. Sound of webm and ogg formats can't be joined from several responses,
+// so synthesis of text which needs more than one chunk fails with ErrUnsupportedFormat for them.
+//
+// Parameters:
+//
+//	size - maximum size of chunk in bytes
+//
+// Returns:
+//
+//	the same speaker to chain calls
+func (s *Speaker) WithChunkSize(size int) *Speaker {
+	s.chunkSize = size
+	return s
+}
+
+// chunks splits text, synthesizes all chunks and yields response chunks in order
+// with word boundary offsets rebased to the start of whole sound
+func (s *Speaker) chunks(ctx context.Context, format OutputFormat) iter.Seq2[tts.ResponseChunk, error] {
+	return func(yield func(tts.ResponseChunk, error) bool) {
+		texts := s.split()
+
+		if len(texts) > 1 && format.IsContainer() {
+			yield(tts.ResponseChunk{}, fmt.Errorf("%w: sound of %s can't be joined from %d chunks", ErrUnsupportedFormat, format, len(texts)))
+			return
+		}
+
+		var turns iter.Seq[iter.Seq2[tts.ResponseChunk, error]]
+		if s.concurrency > 1 && len(texts) > 1 {
+			turns = s.parallelTurns(ctx, texts, format)
+		} else {
+			turns = s.sequentialTurns(ctx, texts, format)
+		}
+
+		// duration of sound data of all previous turns
+		timer := tts.NewAudioTimer(format)
+
+		for turn := range turns {
+			offset := timer.Total()
+
+			for chunk, err := range turn {
+				if err != nil {
+					yield(tts.ResponseChunk{}, err)
+					return
+				}
+
+				switch chunk.ChunkType {
+				case tts.ChunkTypeAudio:
+					timer.Duration(chunk.Data)
+				case tts.ChunkTypeWordBoundary:
+					chunk.Metadata = chunk.Metadata.Shift(offset)
+				}
+
+				if !yield(chunk, nil) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// split splits text into chunks if chunk size or concurrency is set
+func (s *Speaker) split() []string {
+	size := s.chunkSize
+	if size <= 0 && s.concurrency > 1 {
+		size = tts.DefaultMaxChunkSize
+	}
+
+	// SSML document can't be split
+	if size <= 0 || s.args.SSML {
+		return []string{s.text}
+	}
+
+	texts := tts.SplitText(s.text, size)
+	if len(texts) == 0 {
+		// let server side validation report empty text
+		return []string{s.text}
+	}
+
+	return texts
+}
+
+// sequentialTurns synthesizes text chunks one after another
+func (s *Speaker) sequentialTurns(ctx context.Context, texts []string, format OutputFormat) iter.Seq[iter.Seq2[tts.ResponseChunk, error]] {
+	return func(yield func(iter.Seq2[tts.ResponseChunk, error]) bool) {
+		for _, text := range texts {
+			if !yield(s.turn(ctx, text, format)) {
+				return
+			}
+		}
+	}
+}
+
+// parallelTurns synthesizes up to s.concurrency text chunks simultaneously. Next chunk starts only
+// when one of previous chunks is fully consumed, so memory used for buffering is bounded.
+func (s *Speaker) parallelTurns(ctx context.Context, texts []string, format OutputFormat) iter.Seq[iter.Seq2[tts.ResponseChunk, error]] {
+	return func(yield func(iter.Seq2[tts.ResponseChunk, error]) bool) {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		slots := make(chan struct{}, s.concurrency)
+		results := make([]chan chunkResult, len(texts))
+		for i := range results {
+			results[i] = make(chan chunkResult, chunkBufferSize)
+		}
+
+		// start synthesis of next chunk as soon as there is free slot
+		go func() {
+			for i, text := range texts {
+				select {
+				case slots <- struct{}{}:
+				case <-ctx.Done():
+					return
+				}
+
+				go func() {
+					defer close(results[i])
+
+					for chunk, err := range s.turn(ctx, text, format) {
+						select {
+						case results[i] <- chunkResult{chunk: chunk, err: err}:
+						case <-ctx.Done():
+							return
+						}
+					}
+				}()
+			}
+		}()
+
+		for i := range texts {
+			turn := func(yield func(tts.ResponseChunk, error) bool) {
+				for {
+					select {
+					case result, ok := <-results[i]:
+						if !ok {
+							return
+						}
+
+						if !yield(result.chunk, result.err) {
+							return
+						}
+					case <-ctx.Done():
+						yield(tts.ResponseChunk{}, ctx.Err())
+						return
+					}
+				}
+			}
+
+			if !yield(turn) {
+				return
+			}
+
+			// free slot for next chunk
+			<-slots
+		}
+	}
+}
+
+// turn synthesizes one text chunk in separate request to Edge TTS server
+func (s *Speaker) turn(ctx context.Context, text string, format OutputFormat) iter.Seq2[tts.ResponseChunk, error] {
+	return s.etts.synthesize(ctx, text, s.args, format)
+}
@@ -0,0 +1,188 @@
+package edgetts
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_chunks(t *testing.T) {
+	sentences := []string{"One two.", "Three four.", "Five six.", "Seven eight.", "Nine ten."}
+	text := strings.Join(sentences, " ")
+
+	tests := []struct {
+		name        string
+		chunkSize   int
+		concurrency int
+		respond     func(text string) bool
+		texts       []string
+	}{
+		{
+			name:  "whole text by default",
+			texts: []string{text},
+		},
+		{
+			name:      "sequential chunks",
+			chunkSize: 14,
+			texts:     sentences,
+		},
+		{
+			name:        "parallel chunks in order",
+			chunkSize:   14,
+			concurrency: 3,
+			respond: func(text string) bool {
+				// the first chunks are the slowest ones
+				if text == "One two." || text == "Three four." {
+					time.Sleep(100 * time.Millisecond)
+				}
+				return true
+			},
+			texts: sentences,
+		},
+		{
+			name:        "default chunk size with concurrency",
+			concurrency: 3,
+			texts:       []string{text},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newEdgeServer(t)
+			server.setRespond(tt.respond)
+
+			speaker := newTestEdgeTTS(server).Speak(text).WithChunkSize(tt.chunkSize).WithConcurrency(tt.concurrency)
+
+			sound, err := speaker.GetSound(t.Context(), OutputFormatRaw24000)
+			if err != nil {
+				t.Fatalf("expected error to be 'nil', but got '%v'", err)
+			}
+
+			if want := strings.Join(tt.texts, ""); string(sound) != want {
+				t.Errorf("expected sound to be '%s', but got '%s'", want, sound)
+			}
+
+			_, _, texts := server.stats()
+			slices.Sort(texts)
+			want := slices.Sorted(slices.Values(tt.texts))
+
+			if !slices.Equal(texts, want) {
+				t.Errorf("expected requests to be '%q', but got '%q'", want, texts)
+			}
+		})
+	}
+}
+
+func Test_chunksContainerFormat(t *testing.T) {
+	tests := []struct {
+		name      string
+		text      string
+		chunkSize int
+		wantErr   error
+	}{
+		{
+			name:      "one chunk",
+			text:      "One two.",
+			chunkSize: 14,
+		},
+		{
+			name:      "several chunks",
+			text:      "One two. Three four.",
+			chunkSize: 14,
+			wantErr:   ErrUnsupportedFormat,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newEdgeServer(t)
+
+			speaker := newTestEdgeTTS(server).Speak(tt.text).WithChunkSize(tt.chunkSize)
+
+			if _, err := speaker.GetSound(t.Context(), OutputFormatOgg); !errors.Is(err, tt.wantErr) {
+				t.Errorf("expected error to be '%v', but got '%v'", tt.wantErr, err)
+			}
+
+			if connections, _, _ := server.stats(); tt.wantErr != nil && connections != 0 {
+				t.Errorf("expected no requests, but got %d connections", connections)
+			}
+		})
+	}
+}
+
+func Test_chunksBuffering(t *testing.T) {
+	server := newEdgeServer(t)
+
+	text := "One two. Three four. Five six. Seven eight."
+	speaker := newTestEdgeTTS(server).Speak(text).WithChunkSize(12).WithConcurrency(2)
+
+	requests := func() int {
+		_, _, texts := server.stats()
+		return len(texts)
+	}
+
+	var sound []byte
+	for data, err := range speaker.GetSoundIter(t.Context(), OutputFormatRaw24000) {
+		if err != nil {
+			t.Fatalf("expected error to be 'nil', but got '%v'", err)
+		}
+
+		if len(sound) == 0 {
+			// the first chunk is not consumed yet, so only 2 chunks can be synthesized
+			for deadline := time.Now().Add(time.Second); requests() < 2 && time.Now().Before(deadline); {
+				time.Sleep(time.Millisecond)
+			}
+			time.Sleep(50 * time.Millisecond)
+
+			if n := requests(); n != 2 {
+				t.Errorf("expected 2 chunks to be requested while the first one is consumed, but got %d", n)
+			}
+		}
+
+		sound = append(sound, data...)
+	}
+
+	if string(sound) != "One two.Three four.Five six.Seven eight." {
+		t.Errorf("unexpected sound '%s'", sound)
+	}
+
+	if n := requests(); n != 4 {
+		t.Errorf("expected 4 chunks to be requested, but got %d", n)
+	}
+}
+
+func Test_chunksMetadataOffsets(t *testing.T) {
+	server := newEdgeServer(t)
+
+	speaker := newTestEdgeTTS(server).Speak("One two. Three four.").WithChunkSize(12).WithConcurrency(2)
+
+	if _, err := speaker.GetSound(t.Context(), OutputFormatRaw24000); err != nil {
+		t.Fatalf("expected error to be 'nil', but got '%v'", err)
+	}
+
+	metadata, err := speaker.GetMetadata()
+	if err != nil {
+		t.Fatalf("expected error to be 'nil', but got '%v'", err)
+	}
+
+	// the second chunk starts after sound of the first one: 8 bytes of 16 bit 24khz PCM
+	shift := time.Duration(len("One two.")) * time.Second / time.Duration(OutputFormatRaw24000.BytesPerSecond())
+	word := 100 * time.Millisecond
+
+	want := []time.Duration{0, word, shift, shift + word}
+
+	var starts []time.Duration
+	for _, m := range metadata {
+		starts = append(starts, m.Start)
+	}
+
+	if !slices.Equal(starts, want) {
+		t.Errorf("expected word starts to be '%v', but got '%v'", want, starts)
+	}
+
+	if metadata[3].Offset != int((shift+word)/time.Millisecond) {
+		t.Errorf("expected millisecond offset to be calculated from precise one, but got '%+v'", metadata[3])
+	}
+}
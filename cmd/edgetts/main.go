@@ -0,0 +1,147 @@
+// Command edgetts is a command line tool to work with Microsoft Edge's online text-to-speech service.
+//
+// Usage:
+//
+//	edgetts voices [-o file]                     save live voice catalog as JSON
+//	edgetts diff [-exit-code] old.json [new.json] compare voice catalogs
+//
+// When new.json is omitted, diff compares old.json against live voice catalog.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/kolonist/edgetts"
+)
+
+const usage = `Usage:
+  edgetts voices [-o file]                      save live voice catalog as JSON
+  edgetts diff [-exit-code] old.json [new.json] compare voice catalogs
+
+When new.json is omitted, diff compares old.json against live voice catalog.
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	var err error
+	code := 0
+
+	switch os.Args[1] {
+	case "voices":
+		err = runVoices(os.Args[2:])
+	case "diff":
+		code, err = runDiff(os.Args[2:])
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(2)
+	}
+
+	os.Exit(code)
+}
+
+func runVoices(args []string) error {
+	flags := flag.NewFlagSet("voices", flag.ExitOnError)
+	output := flags.String("o", "", "file to write voice catalog to (default stdout)")
+	flags.Parse(args)
+
+	voices, err := edgetts.ListVoices(context.Background())
+	if err != nil {
+		return err
+	}
+
+	if *output == "" {
+		return edgetts.WriteVoices(os.Stdout, voices)
+	}
+
+	file, err := os.Create(*output)
+	if err != nil {
+		return err
+	}
+
+	if err := edgetts.WriteVoices(file, voices); err != nil {
+		file.Close()
+		return err
+	}
+
+	// snapshot is used by diff later, so failed flush must not be ignored
+	return file.Close()
+}
+
+// runDiff prints catalog differences and returns exit code 1 if -exit-code is set and catalogs differ,
+// exit code 2 on wrong arguments
+func runDiff(args []string) (int, error) {
+	flags := flag.NewFlagSet("diff", flag.ExitOnError)
+	exitCode := flags.Bool("exit-code", false, "exit with code 1 if catalogs differ")
+	flags.Parse(args)
+
+	if flags.NArg() < 1 || flags.NArg() > 2 {
+		fmt.Fprint(os.Stderr, usage)
+		return 2, nil
+	}
+
+	oldVoices, err := readVoicesFile(flags.Arg(0))
+	if err != nil {
+		return 0, err
+	}
+
+	var newVoices []edgetts.Voice
+	if flags.NArg() == 2 {
+		newVoices, err = readVoicesFile(flags.Arg(1))
+	} else {
+		newVoices, err = edgetts.ListVoices(context.Background())
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	diff := edgetts.DiffVoices(oldVoices, newVoices)
+
+	for _, v := range diff.Added {
+		fmt.Printf("+ %s (%s, %s)\n", v.ShortName, v.Locale, v.Status)
+	}
+
+	for _, v := range diff.Removed {
+		fmt.Printf("- %s (%s, %s)\n", v.ShortName, v.Locale, v.Status)
+	}
+
+	for _, c := range diff.Changed {
+		fmt.Printf("~ %s: %s\n", c.New.ShortName, strings.Join(c.Fields, ", "))
+		if c.StatusChanged() {
+			fmt.Printf("    Status: %s -> %s\n", c.Old.Status, c.New.Status)
+		}
+	}
+
+	if *exitCode && !diff.Empty() {
+		return 1, nil
+	}
+
+	return 0, nil
+}
+
+func readVoicesFile(filename string) ([]edgetts.Voice, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	voices, err := edgetts.ReadVoices(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read voices from '%s': %v", filename, err)
+	}
+
+	return voices, nil
+}
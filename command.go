@@ -0,0 +1,205 @@
+package edgetts
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// ErrUnsupportedFormat is returned by synthesizer which can't produce requested output format
+var ErrUnsupportedFormat = errors.New("output format not supported")
+
+// commandWaitDelay is time to wait for output of killed command, e.g. if its children still keep stderr open
+const commandWaitDelay = time.Second
+
+// CommandSynthesizer is Synthesizer which runs local command, e.g. espeak-ng or piper.
+// Command reads text from stdin and writes sound data to stdout
+type CommandSynthesizer struct {
+	// Command name and arguments. Placeholders {voice}, {rate}, {volume} and {pitch} in arguments
+	// are replaced by values of request args
+	Command []string
+
+	// Format of sound data written by command. Requests of other formats fail with ErrUnsupportedFormat,
+	// so Registry falls back to the next synthesizer
+	Format OutputFormat
+}
+
+// commandStream reads sound data from stdout of running command
+type commandStream struct {
+	stdout *bufio.Reader
+	pipe   *os.File
+	cmd    *exec.Cmd
+
+	// closed when command exited, err is set before that
+	exited chan struct{}
+	err    error
+}
+
+// NewCommandSynthesizer creates synthesizer which runs command.
+//
+// Parameters:
+//
+//	format - format of sound data written by command
+//	name - command to run
+//	args - arguments of command, may contain placeholders {voice}, {rate}, {volume} and {pitch}
+//
+// Returns:
+//
+//	new synthesizer
+func NewCommandSynthesizer(format OutputFormat, name string, args ...string) *CommandSynthesizer {
+	return &CommandSynthesizer{
+		Command: append([]string{name}, args...),
+		Format:  format,
+	}
+}
+
+// Synthesize implements Synthesizer. Command is killed when ctx is done.
+//
+// Parameters:
+//
+//	ctx - context to stop operation before it finished
+//	request - text and parameters of speech, SSML document is passed to command as is
+//
+// Returns:
+//
+//	stream of sound data written by command. Should be closed after use
+//	error if command failed before writing sound data or request format differs from command format
+func (c *CommandSynthesizer) Synthesize(ctx context.Context, request SynthesisRequest) (AudioStream, error) {
+	if len(c.Command) == 0 {
+		return nil, fmt.Errorf("command not specified")
+	}
+
+	if request.Format != OutputFormatAuto && request.Format != c.Format {
+		return nil, fmt.Errorf("%w by command %s: %s", ErrUnsupportedFormat, c.Command[0], request.Format)
+	}
+
+	replacer := strings.NewReplacer(
+		"{voice}", request.Args.Voice,
+		"{rate}", request.Args.Rate,
+		"{volume}", request.Args.Volume,
+		"{pitch}", request.Args.Pitch,
+	)
+
+	args := make([]string, len(c.Command)-1)
+	for i, arg := range c.Command[1:] {
+		args[i] = replacer.Replace(arg)
+	}
+
+	cmd := exec.CommandContext(ctx, c.Command[0], args...)
+	cmd.Stdin = strings.NewReader(request.Text)
+
+	cmd.WaitDelay = commandWaitDelay
+
+	stderr := &bytes.Buffer{}
+	cmd.Stderr = stderr
+
+	// own pipe instead of StdoutPipe() lets command be waited for while its output is still read
+	pipe, stdout, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+	cmd.Stdout = stdout
+
+	err = cmd.Start()
+	stdout.Close()
+
+	if err != nil {
+		pipe.Close()
+		return nil, err
+	}
+
+	stream := &commandStream{
+		stdout: bufio.NewReader(pipe),
+		pipe:   pipe,
+		cmd:    cmd,
+		exited: make(chan struct{}),
+	}
+
+	go stream.waitExit(stderr)
+
+	// wait for the first sound data to report failure of command
+	if _, err := stream.stdout.Peek(1); err != nil {
+		pipe.Close()
+
+		if err := stream.wait(); err != nil {
+			return nil, err
+		}
+
+		return nil, fmt.Errorf("command %s wrote no sound data", c.Command[0])
+	}
+
+	return stream, nil
+}
+
+func (s *commandStream) Read(p []byte) (int, error) {
+	n, err := s.stdout.Read(p)
+	if err == io.EOF {
+		if err := s.wait(); err != nil {
+			return n, err
+		}
+	}
+
+	return n, err
+}
+
+// Close kills command if it is still running. Returns error of command if it failed by itself
+func (s *commandStream) Close() error {
+	defer s.pipe.Close()
+
+	select {
+	case <-s.exited:
+	default:
+		// error means command exited right before kill
+		s.cmd.Process.Kill()
+	}
+
+	if err := s.wait(); err != nil && !killed(err) {
+		return err
+	}
+
+	return nil
+}
+
+// Metadata returns nil because command doesn't report word boundaries
+func (s *commandStream) Metadata() []SpeechMetadata {
+	return nil
+}
+
+// wait waits for command to exit and returns its error
+func (s *commandStream) wait() error {
+	<-s.exited
+	return s.err
+}
+
+// waitExit waits for command to exit and saves its error with stderr output
+func (s *commandStream) waitExit(stderr *bytes.Buffer) {
+	defer close(s.exited)
+
+	if err := s.cmd.Wait(); err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			err = fmt.Errorf("%w: %s", err, message)
+		}
+
+		s.err = err
+	}
+}
+
+// killed reports whether command exited because it was killed
+func killed(err error) bool {
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		return false
+	}
+
+	status, ok := exitErr.Sys().(syscall.WaitStatus)
+
+	return ok && status.Signaled() && status.Signal() == syscall.SIGKILL
+}
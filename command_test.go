@@ -0,0 +1,157 @@
+package edgetts
+
+import (
+	"errors"
+	"io"
+	"os/exec"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// shellSynthesizer returns synthesizer which runs script with /bin/sh
+func shellSynthesizer(t *testing.T, script string) *CommandSynthesizer {
+	t.Helper()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("test commands need /bin/sh")
+	}
+
+	return NewCommandSynthesizer(OutputFormatRaw24000, "/bin/sh", "-c", script)
+}
+
+func Test_CommandSynthesizer(t *testing.T) {
+	request := SynthesisRequest{
+		Text:   "Hello world",
+		Args:   Args{Voice: testVoice, Rate: "+10%"},
+		Format: OutputFormatRaw24000,
+	}
+
+	tests := []struct {
+		name    string
+		script  string
+		data    string
+		wantErr string
+	}{
+		{
+			name:   "text from stdin",
+			script: "cat",
+			data:   "Hello world",
+		},
+		{
+			name:   "placeholders",
+			script: "printf %s {voice}/{rate}",
+			data:   testVoice + "/+10%",
+		},
+		{
+			name:    "fails before output",
+			script:  "echo broken voice >&2; exit 3",
+			wantErr: "exit status 3: broken voice",
+		},
+		{
+			name:    "no output",
+			script:  "true",
+			wantErr: "wrote no sound data",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stream, err := shellSynthesizer(t, tt.script).Synthesize(t.Context(), request)
+
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("expected error to contain '%s', but got '%v'", tt.wantErr, err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("expected error to be 'nil', but got '%v'", err)
+			}
+
+			data, err := io.ReadAll(stream)
+			if err != nil {
+				t.Errorf("expected error to be 'nil', but got '%v'", err)
+			}
+
+			if string(data) != tt.data {
+				t.Errorf("expected sound to be '%s', but got '%s'", tt.data, data)
+			}
+
+			if err := stream.Close(); err != nil {
+				t.Errorf("expected error to be 'nil', but got '%v'", err)
+			}
+		})
+	}
+}
+
+func Test_CommandSynthesizerFormat(t *testing.T) {
+	synthesizer := shellSynthesizer(t, "cat")
+
+	if _, err := synthesizer.Synthesize(t.Context(), SynthesisRequest{Text: "Hello", Format: OutputFormatMp3}); err == nil {
+		t.Error("expected error of unsupported format, but got 'nil'")
+	}
+
+	stream, err := synthesizer.Synthesize(t.Context(), SynthesisRequest{Text: "Hello", Format: OutputFormatAuto})
+	if err != nil {
+		t.Fatalf("expected auto format to be accepted, but got '%v'", err)
+	}
+	stream.Close()
+}
+
+func Test_CommandStreamClose(t *testing.T) {
+	tests := []struct {
+		name    string
+		script  string
+		exited  bool
+		wantErr bool
+	}{
+		{
+			name:   "running command killed",
+			script: "printf data; sleep 10",
+		},
+		{
+			name:   "exited command",
+			script: "printf data",
+			exited: true,
+		},
+		{
+			name:    "failed command",
+			script:  "printf data; exit 2",
+			exited:  true,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stream, err := shellSynthesizer(t, tt.script).Synthesize(t.Context(), SynthesisRequest{Text: "Hello"})
+			if err != nil {
+				t.Fatalf("expected error to be 'nil', but got '%v'", err)
+			}
+
+			if tt.exited {
+				<-stream.(*commandStream).exited
+			}
+
+			start := time.Now()
+			err = stream.Close()
+
+			if elapsed := time.Since(start); elapsed > 3*commandWaitDelay {
+				t.Errorf("expected running command to be killed, but close took %v", elapsed)
+			}
+
+			var exitErr *exec.ExitError
+			if tt.wantErr && (!errors.As(err, &exitErr) || exitErr.ExitCode() != 2) {
+				t.Errorf("expected exit status 2, but got '%v'", err)
+			}
+
+			if !tt.wantErr && err != nil {
+				t.Errorf("expected error to be 'nil', but got '%v'", err)
+			}
+		})
+	}
+}
@@ -2,6 +2,10 @@
 package edgetts
 
 import (
+	"context"
+
+	"github.com/kolonist/edgetts/internal/flight"
+	"github.com/kolonist/edgetts/internal/throttle"
 	"github.com/kolonist/edgetts/internal/tts"
 )
 
@@ -11,6 +15,24 @@ type Args = tts.Args
 // EdgeTTS used to generate speech from text
 type EdgeTTS struct {
 	args Args
+
+	// connections to Edge TTS server
+	pool *tts.Pool
+
+	// synthesized sound, nil if caching is disabled
+	cache Cache
+
+	// running requests shared by identical syntheses, nil if deduplication is disabled
+	flights *flight.Group
+
+	// request rate and connections limits, nil if not limited
+	limiter     *throttle.Limiter
+	connections *throttle.Semaphore
+	failFast    bool
+
+	// circuit breaker and adaptive connections limit, nil if disabled
+	breaker  *throttle.Breaker
+	adaptive *throttle.Adaptive
 }
 
 // New creates EdgeTTS struct with arguments to generate speech.
@@ -18,14 +40,56 @@ type EdgeTTS struct {
 // Parameters:
 //
 //	args - parameters of speech generation like voice, rate, volume
+//	options - optional settings like WithConnectionPool()
 //
 // Returns:
 //
 //	New EdgeTTS struct
-func New(args Args) *EdgeTTS {
-	return &EdgeTTS{
+func New(args Args, options ...Option) *EdgeTTS {
+	etts := &EdgeTTS{
 		args: args,
+		pool: tts.NewPool(0),
 	}
+
+	for _, option := range options {
+		option(etts)
+	}
+
+	if etts.connections != nil {
+		etts.pool.SetConnectionLimit(etts.acquireConnection, etts.connections.Release)
+	}
+
+	return etts
+}
+
+// ErrClosed is returned by Warmup() after Close()
+var ErrClosed = tts.ErrPoolClosed
+
+// Warmup opens connection to Edge TTS server ahead of time: resolves DNS, makes TLS and websocket handshakes
+// and sends speech configuration for format. Next Speak() only sends text, so the first sound comes faster.
+// Warm connection is closed if it is not used during idle timeout, see WithIdleTimeout().
+// Other formats are also served by warm connection, they just need to send new speech configuration first.
+//
+// Parameters:
+//
+//	ctx - context to stop operation before it finished
+//	format - format of sound data expected in next Speak(). Use one of OutputFormat* constants
+//
+// Returns:
+//
+//	error if connection failed, ErrClosed after Close()
+func (etts *EdgeTTS) Warmup(ctx context.Context, format OutputFormat) error {
+	return etts.pool.Warmup(ctx, format)
+}
+
+// Close closes idle connections to Edge TTS server. Connections of syntheses still running are closed when they
+// finish, next syntheses use new connection for every request.
+//
+// Returns:
+//
+//	error if closing failed
+func (etts *EdgeTTS) Close() error {
+	return etts.pool.Close()
 }
 
 // Speak define text you need to convert to speech
@@ -39,6 +103,7 @@ func New(args Args) *EdgeTTS {
 //	speaker struct to use get synthesized sound
 func (etts *EdgeTTS) Speak(text string) *Speaker {
 	return &Speaker{
+		etts:     etts,
 		text:     text,
 		args:     etts.args,
 		ready:    false,
@@ -58,6 +123,7 @@ func (etts *EdgeTTS) Speak(text string) *Speaker {
 //	speaker struct to use get synthesized sound
 func (etts *EdgeTTS) SpeakWithVoice(text string, voice string) *Speaker {
 	speaker := &Speaker{
+		etts:     etts,
 		text:     text,
 		args:     etts.args,
 		ready:    false,
@@ -0,0 +1,243 @@
+package edgetts
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	testVoice = "en-US-AvaNeural"
+
+	// word boundary offsets step of edgeServer responses in server ticks, 100ms
+	testWordTicks = 1_000_000
+)
+
+// edgeServer is local websocket server which answers SSML requests like Edge TTS server does.
+// Sound data of response is spoken text itself, so tests can check what was synthesized and in which order.
+// Every word of text gets word boundary metadata with offset of testWordTicks * word index.
+type edgeServer struct {
+	url string
+
+	// called with spoken text before response, can delay it. Returns false to leave request unanswered
+	respond func(text string) bool
+
+	// receives value every time client closes connection
+	disconnected chan struct{}
+
+	mu          sync.Mutex
+	connections int
+	formats     []string
+	texts       []string
+}
+
+// newEdgeServer starts server which answers every request
+func newEdgeServer(t *testing.T) *edgeServer {
+	s := &edgeServer{
+		disconnected: make(chan struct{}, 64),
+	}
+
+	upgrader := websocket.Upgrader{
+		CheckOrigin: func(r *http.Request) bool { return true },
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		s.mu.Lock()
+		s.connections++
+		s.mu.Unlock()
+
+		defer func() {
+			select {
+			case s.disconnected <- struct{}{}:
+			default:
+			}
+		}()
+
+		for {
+			messageType, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+
+			message, err := ParseProtocolMessage(messageType == websocket.BinaryMessage, data)
+			if err != nil {
+				return
+			}
+
+			switch message.Path {
+			case "speech.config":
+				s.configure(message.Body)
+			case "ssml":
+				if err := s.answer(conn, message); err != nil {
+					return
+				}
+			}
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	s.url = "ws" + strings.TrimPrefix(server.URL, "http")
+
+	return s
+}
+
+// configure records output format of speech.config
+func (s *edgeServer) configure(body []byte) {
+	var config struct {
+		Context struct {
+			Synthesis struct {
+				Audio struct {
+					OutputFormat string `json:"outputFormat"`
+				} `json:"audio"`
+			} `json:"synthesis"`
+		} `json:"context"`
+	}
+	json.Unmarshal(body, &config)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.formats = append(s.formats, config.Context.Synthesis.Audio.OutputFormat)
+}
+
+// answer sends response to SSML request
+func (s *edgeServer) answer(conn *websocket.Conn, message ProtocolMessage) error {
+	text := ssmlTagRegexp.ReplaceAllString(string(message.Body), "")
+
+	s.mu.Lock()
+	s.texts = append(s.texts, text)
+	respond := s.respond
+	s.mu.Unlock()
+
+	if respond != nil && !respond(text) {
+		return nil
+	}
+
+	requestID := message.RequestID()
+	headers := "X-RequestId:" + requestID + "\r\nContent-Type:application/json; charset=utf-8\r\n"
+
+	type word struct {
+		Type string
+		Data struct {
+			Offset   int64
+			Duration int64
+			Text     struct {
+				Text string
+			} `json:"text"`
+		}
+	}
+
+	var metadata struct {
+		Metadata []word
+	}
+
+	for i, text := range strings.Fields(text) {
+		var w word
+		w.Type = "WordBoundary"
+		w.Data.Offset = int64(i) * testWordTicks
+		w.Data.Duration = testWordTicks * 8 / 10
+		w.Data.Text.Text = text
+
+		metadata.Metadata = append(metadata.Metadata, w)
+	}
+
+	metadataJSON, err := json.Marshal(metadata)
+	if err != nil {
+		return err
+	}
+
+	audioHeaders := "X-RequestId:" + requestID + "\r\nContent-Type:audio/mpeg\r\nPath:audio\r\n"
+	audio := binary.BigEndian.AppendUint16(nil, uint16(len(audioHeaders)))
+	audio = append(audio, audioHeaders...)
+	audio = append(audio, text...)
+
+	messages := []struct {
+		messageType int
+		data        []byte
+	}{
+		{websocket.TextMessage, []byte(headers + "Path:turn.start\r\n\r\n{}")},
+		{websocket.TextMessage, []byte(headers + "Path:audio.metadata\r\n\r\n" + string(metadataJSON))},
+		{websocket.BinaryMessage, audio},
+		{websocket.TextMessage, []byte(headers + "Path:turn.end\r\n\r\n{}")},
+	}
+
+	for _, message := range messages {
+		if err := conn.WriteMessage(message.messageType, message.data); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// setRespond sets function called before every response
+func (s *edgeServer) setRespond(respond func(text string) bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.respond = respond
+}
+
+// stats returns count of accepted connections, formats of speech.config and texts of SSML requests
+func (s *edgeServer) stats() (int, []string, []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.connections, append([]string(nil), s.formats...), append([]string(nil), s.texts...)
+}
+
+// waitDisconnected waits until client closes n connections
+func (s *edgeServer) waitDisconnected(t *testing.T, n int) {
+	t.Helper()
+
+	timeout := time.After(5 * time.Second)
+	for i := range n {
+		select {
+		case <-s.disconnected:
+		case <-timeout:
+			t.Fatalf("expected %d connections to be closed, but only %d were closed", n, i)
+		}
+	}
+}
+
+// newTestEdgeTTS creates EdgeTTS connected to server
+func newTestEdgeTTS(server *edgeServer, options ...Option) *EdgeTTS {
+	return New(Args{Voice: testVoice}, append([]Option{WithEndpoint(server.url)}, options...)...)
+}
+
+func Test_edgeServer(t *testing.T) {
+	server := newEdgeServer(t)
+
+	speaker := newTestEdgeTTS(server).Speak("Hello world")
+
+	sound, err := speaker.GetSound(t.Context(), OutputFormatRaw24000)
+	if err != nil {
+		t.Fatalf("expected error to be 'nil', but got '%v'", err)
+	}
+
+	if string(sound) != "Hello world" {
+		t.Errorf("expected sound to be 'Hello world', but got '%s'", sound)
+	}
+
+	metadata, err := speaker.GetMetadata()
+	if err != nil {
+		t.Fatalf("expected error to be 'nil', but got '%v'", err)
+	}
+
+	if len(metadata) != 2 || metadata[1].Text != "world" || metadata[1].Start != 100*time.Millisecond {
+		t.Errorf("unexpected metadata '%+v'", metadata)
+	}
+}
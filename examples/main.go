@@ -32,7 +32,7 @@ func main() {
 		fmt.Println()
 
 		// use first found male english voice
-		if voice == "" && v.Locale == "en-US" && v.Gender == "Male" {
+		if voice == "" && v.Locale == "en-US" && v.Gender == edgetts.GenderMale {
 			voice = v.ShortName
 		}
 	}
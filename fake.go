@@ -0,0 +1,85 @@
+package edgetts
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+
+	"github.com/kolonist/edgetts/internal/fake"
+)
+
+// ssmlTagRegexp matches SSML tags which are not spoken
+var ssmlTagRegexp = regexp.MustCompile(`<[^>]*>`)
+
+// FakeSynthesizer is Synthesizer which generates deterministic sound data and word boundaries
+// without network, e.g. for tests of code which depends on Synthesizer.
+// Every word of text takes equal time defined by speaking rate, sound is sine tone or silence.
+// Supported formats are mp3 (always silence made of valid frames), raw PCM, mu-law, A-law and WAV.
+type FakeSynthesizer struct {
+	// Speaking rate in words per minute, 0 means 150
+	WordsPerMinute int
+
+	// Frequency of tone in hz, 0 means silence
+	Tone float64
+}
+
+// fakeStream is sound data generated by FakeSynthesizer
+type fakeStream struct {
+	*bytes.Reader
+	metadata []SpeechMetadata
+}
+
+// Close does nothing, sound data is generated in memory
+func (s *fakeStream) Close() error {
+	return nil
+}
+
+// Metadata returns word boundaries, they are available before stream is read
+func (s *fakeStream) Metadata() []SpeechMetadata {
+	return s.metadata
+}
+
+// Synthesize implements Synthesizer. Args of request are ignored except SSML: tags of SSML document are not spoken.
+//
+// Parameters:
+//
+//	ctx - context to stop operation before it finished
+//	request - text and format of speech
+//
+// Returns:
+//
+//	stream of generated sound data
+//	error if text is empty or format is not supported, ErrUnsupportedFormat for opus formats
+func (f *FakeSynthesizer) Synthesize(ctx context.Context, request SynthesisRequest) (AudioStream, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	text := request.Text
+	if request.Args.SSML {
+		text = ssmlTagRegexp.ReplaceAllString(text, " ")
+	}
+
+	metadata, duration := fake.Metadata(text, f.WordsPerMinute)
+	if len(metadata) == 0 {
+		return nil, fmt.Errorf("text not specified")
+	}
+
+	audio, err := fake.Audio(request.Format, duration, f.Tone)
+	if err != nil {
+		if errors.Is(err, fake.ErrUnsupportedFormat) {
+			return nil, fmt.Errorf("%w by fake synthesizer: %s", ErrUnsupportedFormat, request.Format)
+		}
+
+		return nil, err
+	}
+
+	stream := &fakeStream{
+		Reader:   bytes.NewReader(audio),
+		metadata: metadata,
+	}
+
+	return stream, nil
+}
@@ -0,0 +1,106 @@
+package edgetts
+
+import (
+	"errors"
+	"io"
+	"slices"
+	"testing"
+)
+
+func Test_FakeSynthesizer(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		ssml  bool
+		words []string
+	}{
+		{
+			name:  "plain text",
+			text:  "Hello, world!",
+			words: []string{"Hello", "world"},
+		},
+		{
+			name:  "ssml tags are not spoken",
+			text:  `<speak><voice name="en-US-AvaNeural">Hello<break time="1s"/>world</voice></speak>`,
+			ssml:  true,
+			words: []string{"Hello", "world"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := SynthesisRequest{Text: tt.text, Args: Args{SSML: tt.ssml}, Format: OutputFormatRaw24000}
+
+			stream, err := (&FakeSynthesizer{}).Synthesize(t.Context(), request)
+			if err != nil {
+				t.Fatalf("expected error to be 'nil', but got '%v'", err)
+			}
+			defer stream.Close()
+
+			// word boundaries are available before stream is read
+			var words []string
+			for _, m := range stream.Metadata() {
+				words = append(words, m.Text)
+			}
+
+			if !slices.Equal(words, tt.words) {
+				t.Errorf("expected words to be '%q', but got '%q'", tt.words, words)
+			}
+
+			data, err := io.ReadAll(stream)
+			if err != nil {
+				t.Fatalf("expected error to be 'nil', but got '%v'", err)
+			}
+
+			if len(data) == 0 {
+				t.Error("expected sound data, but got none")
+			}
+		})
+	}
+}
+
+func Test_FakeSynthesizerFormats(t *testing.T) {
+	tests := []struct {
+		format      OutputFormat
+		unsupported bool
+	}{
+		{OutputFormatMp3, false},
+		{OutputFormatRaw24000, false},
+		{OutputFormatOgg, true},
+		{OutputFormatOgg48000, true},
+		{OutputFormatWebm, true},
+		{OutputFormatWebm24000, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format.String(), func(t *testing.T) {
+			_, err := (&FakeSynthesizer{}).Synthesize(t.Context(), SynthesisRequest{Text: "Hello", Format: tt.format})
+
+			if unsupported := errors.Is(err, ErrUnsupportedFormat); unsupported != tt.unsupported {
+				t.Errorf("expected unsupported format to be %v, but got error '%v'", tt.unsupported, err)
+			}
+
+			if !tt.unsupported && err != nil {
+				t.Errorf("expected error to be 'nil', but got '%v'", err)
+			}
+		})
+	}
+}
+
+func Test_FakeSynthesizerRegistryFallback(t *testing.T) {
+	fallback := &stubSynthesizer{data: "opus"}
+
+	registry := NewRegistry()
+	registry.Register("fake", &FakeSynthesizer{})
+	registry.Register("fallback", fallback)
+
+	stream, err := registry.Synthesize(t.Context(), SynthesisRequest{Text: "Hello", Format: OutputFormatOgg})
+	if err != nil {
+		t.Fatalf("expected error to be 'nil', but got '%v'", err)
+	}
+	defer stream.Close()
+
+	if fallback.calls != 1 {
+		t.Errorf("expected fallback for unsupported format, but got %d calls", fallback.calls)
+	}
+}
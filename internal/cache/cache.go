@@ -0,0 +1,65 @@
+package cache
+
+import (
+	"time"
+
+	"github.com/kolonist/edgetts/internal/tts"
+)
+
+// Entry is synthesized sound of one request to Edge TTS server
+type Entry struct {
+	// Sound data in wire format, i.e. without WAV header
+	Audio []byte `json:"-"`
+
+	// Timings of each word in text
+	Metadata []tts.SpeechMetadata `json:"metadata"`
+
+	// Size of sound data received before each word boundary of Metadata, so replay keeps original order.
+	// Entries without it replay all word boundaries before sound data
+	AudioOffsets []int `json:"audioOffsets,omitempty"`
+}
+
+// Cache stores synthesized sound by hash of synthesis parameters. Implementations should be safe for concurrent use
+type Cache interface {
+	// Get returns entry stored by key
+	Get(key string) (*Entry, bool)
+
+	// Put stores entry by key
+	Put(key string, entry *Entry)
+}
+
+// Options contains cache size limits and eviction settings
+type Options struct {
+	// Maximum total size of stored sound data in bytes, 0 means unlimited
+	MaxBytes int64
+
+	// Maximum count of stored entries, 0 means unlimited
+	MaxEntries int
+
+	// Time after which entry is evicted, 0 means never
+	TTL time.Duration
+}
+
+// size returns approximate size of entry in memory
+func (e *Entry) size() int64 {
+	// offset and duration of each word
+	const metadataSize = 16
+
+	size := int64(len(e.Audio))
+	for _, m := range e.Metadata {
+		size += metadataSize + int64(len(m.Text))
+	}
+	size += 8 * int64(len(e.AudioOffsets))
+
+	return size
+}
+
+// exceeds reports whether total size or entries count exceed limits
+func (o Options) exceeds(size int64, entries int) bool {
+	return (o.MaxBytes > 0 && size > o.MaxBytes) || (o.MaxEntries > 0 && entries > o.MaxEntries)
+}
+
+// expired reports whether entry created at created time is expired
+func (o Options) expired(created time.Time) bool {
+	return o.TTL > 0 && time.Since(created) > o.TTL
+}
@@ -0,0 +1,101 @@
+package cache
+
+import (
+	"bytes"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/kolonist/edgetts/internal/tts"
+)
+
+func newEntry(size int) *Entry {
+	return &Entry{
+		Audio: bytes.Repeat([]byte{1}, size),
+		Metadata: []tts.SpeechMetadata{
+			{Offset: 100, Duration: 200, Text: "word"},
+		},
+	}
+}
+
+func Test_Cache(t *testing.T) {
+	// each entry is 100 bytes of audio plus 20 bytes of metadata in memory and bigger on disk
+	options := Options{MaxEntries: 2}
+
+	file, err := NewFile(t.TempDir(), options)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	caches := []struct {
+		name  string
+		cache Cache
+	}{
+		{"memory", NewMemory(options)},
+		{"file", file},
+	}
+
+	for _, tt := range caches {
+		t.Run(tt.name, func(t *testing.T) {
+			c := tt.cache
+
+			if _, ok := c.Get("a"); ok {
+				t.Fatal("expected miss in empty cache")
+			}
+
+			a := newEntry(100)
+			a.AudioOffsets = []int{50}
+
+			c.Put("a", a)
+			time.Sleep(10 * time.Millisecond)
+			c.Put("b", newEntry(100))
+			time.Sleep(10 * time.Millisecond)
+
+			// make "a" recently used
+			entry, ok := c.Get("a")
+			if !ok {
+				t.Fatal("expected hit for 'a'")
+			}
+
+			if len(entry.Audio) != 100 || !slices.Equal(entry.Metadata, a.Metadata) || !slices.Equal(entry.AudioOffsets, a.AudioOffsets) {
+				t.Errorf("expected entry to be kept as is, but got '%+v'", entry)
+			}
+
+			time.Sleep(10 * time.Millisecond)
+			c.Put("c", newEntry(100))
+
+			if _, ok := c.Get("b"); ok {
+				t.Error("expected least recently used 'b' to be evicted")
+			}
+
+			for _, key := range []string{"a", "c"} {
+				if _, ok := c.Get(key); !ok {
+					t.Errorf("expected hit for '%s'", key)
+				}
+			}
+		})
+	}
+}
+
+func Test_MemoryLimits(t *testing.T) {
+	c := NewMemory(Options{MaxBytes: 250, TTL: 50 * time.Millisecond})
+
+	c.Put("big", newEntry(1000))
+	if _, ok := c.Get("big"); ok {
+		t.Error("expected entry bigger than cache not to be stored")
+	}
+
+	c.Put("a", newEntry(100))
+	c.Put("b", newEntry(100))
+	c.Put("c", newEntry(100))
+
+	if c.Len() != 2 {
+		t.Errorf("expected 2 entries to fit in cache, but got '%v'", c.Len())
+	}
+
+	time.Sleep(60 * time.Millisecond)
+
+	if _, ok := c.Get("c"); ok {
+		t.Error("expected entry to expire")
+	}
+}
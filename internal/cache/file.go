@@ -0,0 +1,208 @@
+package cache
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	audioExt    = ".audio"
+	metadataExt = ".json"
+)
+
+// File is filesystem cache which stores every entry in two files: sound data and JSON metadata.
+// Least recently used entries are evicted by file modification time which is updated on every hit.
+type File struct {
+	mu      sync.Mutex
+	dir     string
+	options Options
+}
+
+// fileMetadata is content of JSON file of entry
+type fileMetadata struct {
+	Entry
+	Created time.Time `json:"created"`
+}
+
+// fileInfo is entry info used in eviction
+type fileInfo struct {
+	key     string
+	size    int64
+	modTime time.Time
+}
+
+// NewFile creates filesystem cache in dir. Directory is created if it does not exist
+func NewFile(dir string, options Options) (*File, error) {
+	if err := os.MkdirAll(dir, 0777); err != nil {
+		return nil, err
+	}
+
+	cache := &File{
+		dir:     dir,
+		options: options,
+	}
+
+	return cache, nil
+}
+
+// Get returns entry stored by key
+func (c *File) Get(key string) (*Entry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	data, err := os.ReadFile(c.path(key, metadataExt))
+	if err != nil {
+		return nil, false
+	}
+
+	var metadata fileMetadata
+	if err := json.Unmarshal(data, &metadata); err != nil {
+		c.remove(key)
+		return nil, false
+	}
+
+	if c.options.expired(metadata.Created) {
+		c.remove(key)
+		return nil, false
+	}
+
+	audio, err := os.ReadFile(c.path(key, audioExt))
+	if err != nil {
+		c.remove(key)
+		return nil, false
+	}
+
+	// mark entry as recently used
+	now := time.Now()
+	os.Chtimes(c.path(key, metadataExt), now, now)
+
+	entry := &Entry{
+		Audio:        audio,
+		Metadata:     metadata.Metadata,
+		AudioOffsets: metadata.AudioOffsets,
+	}
+
+	return entry, true
+}
+
+// Put stores entry by key and evicts least recently used entries if cache exceeds limits
+func (c *File) Put(key string, entry *Entry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.options.exceeds(entry.size(), 1) {
+		return
+	}
+
+	metadata, err := json.Marshal(fileMetadata{
+		Entry:   *entry,
+		Created: time.Now(),
+	})
+	if err != nil {
+		return
+	}
+
+	// metadata file is written last, so entry without it is never read
+	if err := writeFileAtomic(c.path(key, audioExt), entry.Audio); err != nil {
+		return
+	}
+
+	if err := writeFileAtomic(c.path(key, metadataExt), metadata); err != nil {
+		c.remove(key)
+		return
+	}
+
+	c.evict()
+}
+
+// evict removes least recently used entries while cache exceeds limits. Should be called with c.mu locked
+func (c *File) evict() {
+	if c.options.MaxBytes <= 0 && c.options.MaxEntries <= 0 {
+		return
+	}
+
+	files, err := os.ReadDir(c.dir)
+	if err != nil {
+		return
+	}
+
+	var entries []fileInfo
+	var size int64
+
+	for _, file := range files {
+		key, ok := strings.CutSuffix(file.Name(), metadataExt)
+		if !ok || strings.HasPrefix(key, ".") {
+			continue
+		}
+
+		metadataInfo, err := file.Info()
+		if err != nil {
+			continue
+		}
+
+		audioInfo, err := os.Stat(c.path(key, audioExt))
+		if err != nil {
+			continue
+		}
+
+		info := fileInfo{
+			key:     key,
+			size:    audioInfo.Size() + metadataInfo.Size(),
+			modTime: metadataInfo.ModTime(),
+		}
+
+		entries = append(entries, info)
+		size += info.size
+	}
+
+	slices.SortFunc(entries, func(a, b fileInfo) int {
+		return a.modTime.Compare(b.modTime)
+	})
+
+	for len(entries) > 0 && c.options.exceeds(size, len(entries)) {
+		c.remove(entries[0].key)
+		size -= entries[0].size
+		entries = entries[1:]
+	}
+}
+
+// remove removes files of entry. Should be called with c.mu locked
+func (c *File) remove(key string) {
+	os.Remove(c.path(key, metadataExt))
+	os.Remove(c.path(key, audioExt))
+}
+
+func (c *File) path(key string, ext string) string {
+	return filepath.Join(c.dir, key+ext)
+}
+
+// writeFileAtomic writes data to temporary file and renames it to filename
+func writeFileAtomic(filename string, data []byte) error {
+	file, err := os.CreateTemp(filepath.Dir(filename), "."+filepath.Base(filename)+".*.tmp")
+	if err != nil {
+		return err
+	}
+
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return err
+	}
+
+	if err := file.Close(); err != nil {
+		os.Remove(file.Name())
+		return err
+	}
+
+	if err := os.Rename(file.Name(), filename); err != nil {
+		os.Remove(file.Name())
+		return err
+	}
+
+	return nil
+}
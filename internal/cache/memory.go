@@ -0,0 +1,97 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// Memory is in-memory cache which evicts least recently used entries
+type Memory struct {
+	mu      sync.Mutex
+	options Options
+	size    int64
+
+	// most recently used entries are in front
+	lru   *list.List
+	items map[string]*list.Element
+}
+
+type memoryItem struct {
+	key     string
+	entry   *Entry
+	created time.Time
+}
+
+// NewMemory creates in-memory LRU cache
+func NewMemory(options Options) *Memory {
+	return &Memory{
+		options: options,
+		lru:     list.New(),
+		items:   make(map[string]*list.Element),
+	}
+}
+
+// Get returns entry stored by key
+func (c *Memory) Get(key string) (*Entry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+
+	item := element.Value.(*memoryItem)
+	if c.options.expired(item.created) {
+		c.remove(element)
+		return nil, false
+	}
+
+	c.lru.MoveToFront(element)
+
+	return item.entry, true
+}
+
+// Put stores entry by key and evicts least recently used entries if cache exceeds limits
+func (c *Memory) Put(key string, entry *Entry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.items[key]; ok {
+		c.remove(element)
+	}
+
+	// entry which exceeds limits by itself would evict everything
+	if c.options.exceeds(entry.size(), 1) {
+		return
+	}
+
+	item := &memoryItem{
+		key:     key,
+		entry:   entry,
+		created: time.Now(),
+	}
+
+	c.items[key] = c.lru.PushFront(item)
+	c.size += entry.size()
+
+	for c.options.exceeds(c.size, c.lru.Len()) {
+		c.remove(c.lru.Back())
+	}
+}
+
+// Len returns count of stored entries
+func (c *Memory) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.lru.Len()
+}
+
+// remove removes element from cache. Should be called with c.mu locked
+func (c *Memory) remove(element *list.Element) {
+	item := c.lru.Remove(element).(*memoryItem)
+	delete(c.items, item.key)
+	c.size -= item.entry.size()
+}
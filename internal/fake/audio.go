@@ -0,0 +1,184 @@
+// Package fake generates deterministic sound data and word boundaries without Edge TTS server
+package fake
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/kolonist/edgetts/internal/tts"
+)
+
+// ErrUnsupportedFormat is returned for formats which can't be generated, e.g. opus in webm or ogg
+var ErrUnsupportedFormat = errors.New("output format not supported")
+
+// amplitude of tone, about a quarter of maximum 16 bit sample
+const amplitude = 8192
+
+var (
+	// bitrate indexes of MPEG layer III by bitrate in kbps
+	mp3BitrateIndexesV1 = map[int]byte{32: 1, 40: 2, 48: 3, 56: 4, 64: 5, 80: 6, 96: 7, 112: 8, 128: 9, 160: 10, 192: 11, 224: 12, 256: 13, 320: 14}
+	mp3BitrateIndexesV2 = map[int]byte{8: 1, 16: 2, 24: 3, 32: 4, 40: 5, 48: 6, 56: 7, 64: 8, 80: 9, 96: 10, 112: 11, 128: 12, 144: 13, 160: 14}
+
+	// sample rate indexes of MPEG layer III by sample rate in hz
+	mp3SampleRateIndexesV1 = map[int]byte{44_100: 0, 48_000: 1, 32_000: 2}
+	mp3SampleRateIndexesV2 = map[int]byte{22_050: 0, 24_000: 1, 16_000: 2}
+)
+
+// Audio generates sound data of duration in format. Sound is sine tone of frequency in hz or silence
+// if frequency is 0. Mp3 is always silence made of frames with empty side info.
+func Audio(format tts.OutputFormat, duration time.Duration, frequency float64) ([]byte, error) {
+	info := format.Info()
+
+	switch info.Codec {
+	case "mp3":
+		return mp3Silence(info, duration)
+
+	case "pcm", "mulaw", "alaw":
+		samples := pcmSamples(info.SampleRate, duration, frequency)
+		data := encodeSamples(info.Codec, samples)
+
+		if format.IsWav() {
+			data = append(tts.WavHeader(format, int64(len(data))), data...)
+		}
+
+		return data, nil
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrUnsupportedFormat, format)
+}
+
+// pcmSamples generates 16 bit samples of tone
+func pcmSamples(sampleRate int, duration time.Duration, frequency float64) []int16 {
+	samples := make([]int16, int64(sampleRate)*int64(duration)/int64(time.Second))
+
+	if frequency <= 0 {
+		return samples
+	}
+
+	for i := range samples {
+		samples[i] = int16(amplitude * math.Sin(2*math.Pi*frequency*float64(i)/float64(sampleRate)))
+	}
+
+	return samples
+}
+
+// encodeSamples encodes samples as little endian 16 bit PCM or 8 bit G.711
+func encodeSamples(codec string, samples []int16) []byte {
+	switch codec {
+	case "mulaw":
+		data := make([]byte, len(samples))
+		for i, sample := range samples {
+			data[i] = linearToMulaw(sample)
+		}
+
+		return data
+
+	case "alaw":
+		data := make([]byte, len(samples))
+		for i, sample := range samples {
+			data[i] = linearToAlaw(sample)
+		}
+
+		return data
+	}
+
+	data := make([]byte, 2*len(samples))
+	for i, sample := range samples {
+		binary.LittleEndian.PutUint16(data[2*i:], uint16(sample))
+	}
+
+	return data
+}
+
+// mp3Silence generates silent mono MPEG layer III frames. Frame with zero side info has no main data
+// and is decoded as silence
+func mp3Silence(info tts.OutputFormatInfo, duration time.Duration) ([]byte, error) {
+	kbps := info.Bitrate / 1000
+
+	// version bits: 11 is MPEG 1, 10 is MPEG 2
+	version := byte(0x03)
+	bitrateIndex, ok := mp3BitrateIndexesV1[kbps]
+	sampleRateIndex, ok2 := mp3SampleRateIndexesV1[info.SampleRate]
+
+	if !ok2 {
+		version = 0x02
+		bitrateIndex, ok = mp3BitrateIndexesV2[kbps]
+		sampleRateIndex, ok2 = mp3SampleRateIndexesV2[info.SampleRate]
+	}
+
+	if !ok || !ok2 {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedFormat, info.Format)
+	}
+
+	header := []byte{
+		0xFF,
+		0xE0 | version<<3 | 0x01<<1 | 0x01, // layer III, no CRC
+		bitrateIndex<<4 | sampleRateIndex<<2,
+		0x03 << 6, // mono
+	}
+
+	frameSize, frameDuration, ok := tts.ParseMp3FrameHeader(header)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedFormat, info.Format)
+	}
+
+	frames := int((duration + frameDuration - 1) / frameDuration)
+	data := make([]byte, frames*frameSize)
+
+	for i := range frames {
+		copy(data[i*frameSize:], header)
+	}
+
+	return data, nil
+}
+
+// linearToMulaw encodes 16 bit sample with G.711 mu-law
+func linearToMulaw(sample int16) byte {
+	const bias = 0x84
+	const clip = 32635
+
+	s := int(sample)
+	sign := 0
+	if s < 0 {
+		s = -s
+		sign = 0x80
+	}
+
+	s = min(s, clip) + bias
+
+	exponent := 7
+	for mask := 0x4000; s&mask == 0 && exponent > 0; mask >>= 1 {
+		exponent--
+	}
+
+	mantissa := (s >> (exponent + 3)) & 0x0F
+
+	return ^byte(sign | exponent<<4 | mantissa)
+}
+
+// linearToAlaw encodes 16 bit sample with G.711 A-law
+func linearToAlaw(sample int16) byte {
+	s := int(sample) >> 3
+	sign := 0x80
+	if s < 0 {
+		s = -s - 1
+		sign = 0
+	}
+
+	var encoded int
+	if s < 32 {
+		encoded = s >> 1
+	} else {
+		exponent := 1
+		for v := s >> 5; v > 1; v >>= 1 {
+			exponent++
+		}
+
+		encoded = exponent<<4 | (s>>exponent)&0x0F
+	}
+
+	return byte(sign|encoded) ^ 0x55
+}
@@ -0,0 +1,107 @@
+package fake
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/kolonist/edgetts/internal/tts"
+)
+
+func Test_Audio(t *testing.T) {
+	mp3Formats := []tts.OutputFormat{
+		tts.OutputFormatMp3,
+		tts.OutputFormatMp3At16kHz32kbps,
+		tts.OutputFormatMp3At16kHz128kbps,
+		tts.OutputFormatMp3At48kHz192kbps,
+	}
+
+	for _, format := range mp3Formats {
+		t.Run(string(format), func(t *testing.T) {
+			data, err := Audio(format, time.Second, 440)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			timer := tts.NewAudioTimer(format)
+			if duration := timer.Duration(data); duration < time.Second || duration > 1100*time.Millisecond {
+				t.Errorf("expected about 1s of mp3 frames, but got '%v'", duration)
+			}
+		})
+	}
+
+	t.Run("wav", func(t *testing.T) {
+		data, err := Audio(tts.OutputFormatWav16000, time.Second, 440)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(data) != tts.WavHeaderSize+32_000 || string(data[:4]) != "RIFF" {
+			t.Fatalf("expected WAV header and 32000 bytes of samples, but got '%v' bytes", len(data))
+		}
+
+		if size := binary.LittleEndian.Uint32(data[40:44]); size != 32_000 {
+			t.Errorf("expected data size in header to be '32000', but got '%v'", size)
+		}
+
+		if bytes.Count(data[tts.WavHeaderSize:], []byte{0}) == 32_000 {
+			t.Error("expected tone, but got silence")
+		}
+	})
+
+	t.Run("silence", func(t *testing.T) {
+		tests := []struct {
+			format tts.OutputFormat
+			sample byte
+			size   int
+		}{
+			{tts.OutputFormatRaw24000, 0x00, 4800},
+			{tts.OutputFormatMulaw8000, 0xFF, 800},
+			{tts.OutputFormatAlaw8000, 0xD5, 800},
+		}
+		for _, tt := range tests {
+			data, err := Audio(tt.format, 100*time.Millisecond, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !bytes.Equal(data, bytes.Repeat([]byte{tt.sample}, tt.size)) {
+				t.Errorf("expected %v bytes of '%x' silence in %s", tt.size, tt.sample, tt.format)
+			}
+		}
+	})
+
+	t.Run("unsupported", func(t *testing.T) {
+		if _, err := Audio(tts.OutputFormatOgg, time.Second, 0); !errors.Is(err, ErrUnsupportedFormat) {
+			t.Errorf("expected ErrUnsupportedFormat, but got '%v'", err)
+		}
+	})
+}
+
+func Test_Metadata(t *testing.T) {
+	metadata, duration := Metadata("Hello, world! How are - you?", 120)
+
+	expected := []tts.SpeechMetadata{
+		{Offset: 0, Duration: 400, Text: "Hello", Start: 0 * time.Millisecond, Length: 400 * time.Millisecond},
+		{Offset: 500, Duration: 400, Text: "world", Start: 500 * time.Millisecond, Length: 400 * time.Millisecond},
+		{Offset: 1000, Duration: 400, Text: "How", Start: 1000 * time.Millisecond, Length: 400 * time.Millisecond},
+		{Offset: 1500, Duration: 400, Text: "are", Start: 1500 * time.Millisecond, Length: 400 * time.Millisecond},
+		{Offset: 2000, Duration: 400, Text: "you", Start: 2000 * time.Millisecond, Length: 400 * time.Millisecond},
+	}
+
+	if len(metadata) != len(expected) {
+		t.Fatalf("expected '%+v', but got '%+v'", expected, metadata)
+	}
+
+	for i := range expected {
+		if metadata[i] != expected[i] {
+			t.Errorf("expected '%+v', but got '%+v'", expected[i], metadata[i])
+		}
+	}
+
+	if duration != 2500*time.Millisecond {
+		t.Errorf("expected duration to be '2.5s', but got '%v'", duration)
+	}
+}
@@ -0,0 +1,48 @@
+package fake
+
+import (
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/kolonist/edgetts/internal/tts"
+)
+
+// DefaultWordsPerMinute is average speaking rate
+const DefaultWordsPerMinute = 150
+
+// Metadata generates word boundaries of text spoken at wordsPerMinute rate and returns them with duration of speech.
+// Every word takes equal time: 80% of it is pronunciation and 20% is pause before next word.
+// Punctuation around words is not included in word text.
+func Metadata(text string, wordsPerMinute int) ([]tts.SpeechMetadata, time.Duration) {
+	if wordsPerMinute <= 0 {
+		wordsPerMinute = DefaultWordsPerMinute
+	}
+
+	interval := time.Minute / time.Duration(wordsPerMinute)
+	length := interval * 8 / 10
+
+	var metadata []tts.SpeechMetadata
+
+	for field := range strings.FieldsSeq(text) {
+		word := strings.TrimFunc(field, func(r rune) bool {
+			return unicode.IsPunct(r) || unicode.IsSymbol(r)
+		})
+
+		if word == "" {
+			continue
+		}
+
+		offset := time.Duration(len(metadata)) * interval
+
+		metadata = append(metadata, tts.SpeechMetadata{
+			Offset:   int(offset.Milliseconds()),
+			Duration: int(length.Milliseconds()),
+			Text:     word,
+			Start:    offset,
+			Length:   length,
+		})
+	}
+
+	return metadata, time.Duration(len(metadata)) * interval
+}
@@ -0,0 +1,190 @@
+// Package flight coalesces concurrent identical synthesis requests into one upstream request
+package flight
+
+import (
+	"bytes"
+	"context"
+	"iter"
+	"sync"
+
+	"github.com/kolonist/edgetts/internal/tts"
+)
+
+// SynthesizeFunc runs upstream synthesis request
+type SynthesizeFunc func(ctx context.Context) iter.Seq2[tts.ResponseChunk, error]
+
+// Group coalesces concurrent requests with the same key
+type Group struct {
+	mu      sync.Mutex
+	flights map[string]*flight
+}
+
+// flight is one upstream request shared by all subscribers
+type flight struct {
+	mu          sync.Mutex
+	chunks      []tts.ResponseChunk
+	err         error
+	done        bool
+	changed     chan struct{}
+	subscribers int
+	cancel      context.CancelFunc
+}
+
+// Do runs fn or joins already running request with the same key. Every subscriber gets all response chunks
+// from the start with its own copy of sound data. Upstream request is not bound to subscriber context,
+// it is cancelled only when all subscribers leave.
+func (g *Group) Do(ctx context.Context, key string, fn SynthesizeFunc) iter.Seq2[tts.ResponseChunk, error] {
+	return func(yield func(tts.ResponseChunk, error) bool) {
+		if err := ctx.Err(); err != nil {
+			yield(tts.ResponseChunk{}, err)
+			return
+		}
+
+		f := g.join(ctx, key, fn)
+		defer g.leave(key, f)
+
+		for i := 0; ; i++ {
+			chunk, err, ok := f.wait(ctx, i)
+			if !ok {
+				return
+			}
+
+			if err != nil {
+				yield(tts.ResponseChunk{}, err)
+				return
+			}
+
+			chunk.Data = bytes.Clone(chunk.Data)
+
+			if !yield(chunk, nil) {
+				return
+			}
+		}
+	}
+}
+
+// join subscribes to running flight or starts new one
+func (g *Group) join(ctx context.Context, key string, fn SynthesizeFunc) *flight {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if f, ok := g.flights[key]; ok {
+		f.mu.Lock()
+		f.subscribers++
+		f.mu.Unlock()
+
+		return f
+	}
+
+	if g.flights == nil {
+		g.flights = make(map[string]*flight)
+	}
+
+	flightCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+
+	f := &flight{
+		changed:     make(chan struct{}),
+		subscribers: 1,
+		cancel:      cancel,
+	}
+	g.flights[key] = f
+
+	go g.run(flightCtx, key, f, fn)
+
+	return f
+}
+
+// leave unsubscribes from flight and cancels it if there are no subscribers left
+func (g *Group) leave(key string, f *flight) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	f.mu.Lock()
+	f.subscribers--
+	last := f.subscribers == 0
+	f.mu.Unlock()
+
+	if last {
+		f.cancel()
+
+		if g.flights[key] == f {
+			delete(g.flights, key)
+		}
+	}
+}
+
+// run reads upstream response and publishes it to subscribers
+func (g *Group) run(ctx context.Context, key string, f *flight, fn SynthesizeFunc) {
+	defer f.cancel()
+
+	var err error
+	for chunk, chunkErr := range fn(ctx) {
+		if chunkErr != nil {
+			err = chunkErr
+			break
+		}
+
+		f.publish(chunk)
+	}
+
+	// new requests should not join finished flight
+	g.mu.Lock()
+	if g.flights[key] == f {
+		delete(g.flights, key)
+	}
+	g.mu.Unlock()
+
+	f.finish(err)
+}
+
+func (f *flight) publish(chunk tts.ResponseChunk) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.chunks = append(f.chunks, chunk)
+	f.notify()
+}
+
+func (f *flight) finish(err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.err = err
+	f.done = true
+	f.notify()
+}
+
+// notify wakes up waiting subscribers. Should be called with f.mu locked
+func (f *flight) notify() {
+	close(f.changed)
+	f.changed = make(chan struct{})
+}
+
+// wait waits for chunk with index i. Returns false if there are no more chunks
+func (f *flight) wait(ctx context.Context, i int) (tts.ResponseChunk, error, bool) {
+	for {
+		f.mu.Lock()
+		if i < len(f.chunks) {
+			chunk := f.chunks[i]
+			f.mu.Unlock()
+
+			return chunk, nil, true
+		}
+
+		if f.done {
+			err := f.err
+			f.mu.Unlock()
+
+			return tts.ResponseChunk{}, err, err != nil
+		}
+
+		changed := f.changed
+		f.mu.Unlock()
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return tts.ResponseChunk{}, ctx.Err(), true
+		}
+	}
+}
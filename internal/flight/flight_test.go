@@ -0,0 +1,91 @@
+package flight
+
+import (
+	"context"
+	"iter"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kolonist/edgetts/internal/tts"
+)
+
+func Test_Do(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+
+	fn := func(ctx context.Context) iter.Seq2[tts.ResponseChunk, error] {
+		return func(yield func(tts.ResponseChunk, error) bool) {
+			calls.Add(1)
+
+			if !yield(tts.ResponseChunk{ChunkType: tts.ChunkTypeAudio, Data: []byte("first")}, nil) {
+				return
+			}
+
+			select {
+			case <-release:
+			case <-ctx.Done():
+				yield(tts.ResponseChunk{}, ctx.Err())
+				return
+			}
+
+			yield(tts.ResponseChunk{ChunkType: tts.ChunkTypeAudio, Data: []byte("second")}, nil)
+		}
+	}
+
+	group := &Group{}
+
+	// cancelled subscriber must not cancel shared request
+	cancelled, cancel := context.WithCancel(context.Background())
+
+	var wg sync.WaitGroup
+	results := make([]string, 3)
+	errs := make([]error, 3)
+	started := make(chan struct{}, 3)
+
+	for i := range 3 {
+		ctx := context.Background()
+		if i == 0 {
+			ctx = cancelled
+		}
+
+		wg.Go(func() {
+			for chunk, err := range group.Do(ctx, "key", fn) {
+				if err != nil {
+					errs[i] = err
+					return
+				}
+
+				if results[i] == "" {
+					started <- struct{}{}
+				}
+
+				results[i] += string(chunk.Data)
+			}
+		})
+	}
+
+	for range 3 {
+		<-started
+	}
+
+	cancel()
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if n := calls.Load(); n != 1 {
+		t.Errorf("expected one upstream request, but got '%v'", n)
+	}
+
+	if errs[0] == nil {
+		t.Error("expected cancelled subscriber to get error")
+	}
+
+	for i := 1; i < 3; i++ {
+		if errs[i] != nil || results[i] != "firstsecond" {
+			t.Errorf("expected subscriber %d to get whole response, but got '%v', '%v'", i, results[i], errs[i])
+		}
+	}
+}
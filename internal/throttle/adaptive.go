@@ -0,0 +1,46 @@
+package throttle
+
+import "sync"
+
+// Adaptive changes limit of semaphore by feedback from server: halves it on throttling signals
+// and increases it by one after limit successful requests in a row, but not above maximum
+type Adaptive struct {
+	mu        sync.Mutex
+	semaphore *Semaphore
+	max       int
+	successes int
+}
+
+// NewAdaptive creates adaptive limit of semaphore which never exceeds its current limit
+func NewAdaptive(semaphore *Semaphore) *Adaptive {
+	return &Adaptive{
+		semaphore: semaphore,
+		max:       semaphore.Limit(),
+	}
+}
+
+// Success reports successful request
+func (a *Adaptive) Success() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	limit := a.semaphore.Limit()
+	if limit >= a.max {
+		return
+	}
+
+	a.successes++
+	if a.successes >= limit {
+		a.successes = 0
+		a.semaphore.SetLimit(limit + 1)
+	}
+}
+
+// Throttled reports that server throttles requests
+func (a *Adaptive) Throttled() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.successes = 0
+	a.semaphore.SetLimit(a.semaphore.Limit() / 2)
+}
@@ -0,0 +1,124 @@
+package throttle
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is matched by CircuitOpenError with errors.Is()
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// CircuitOpenError is returned while circuit breaker rejects requests after repeated failures
+type CircuitOpenError struct {
+	// Time left till circuit breaker lets probe request through
+	RetryAfter time.Duration
+}
+
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("%v, retry after %v", ErrCircuitOpen, e.RetryAfter.Round(time.Millisecond))
+}
+
+func (e *CircuitOpenError) Is(target error) bool {
+	return target == ErrCircuitOpen
+}
+
+type breakerState int
+
+const (
+	breakerClosed breakerState = iota
+	breakerOpen
+	breakerHalfOpen
+)
+
+// Breaker is circuit breaker. It opens after threshold consecutive failures and rejects requests during cooldown.
+// After cooldown single probe request is let through: its success closes breaker, its failure opens it again.
+type Breaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+
+	state    breakerState
+	failures int
+	openedAt time.Time
+}
+
+// NewBreaker creates circuit breaker which opens after threshold consecutive failures for cooldown
+func NewBreaker(threshold int, cooldown time.Duration) *Breaker {
+	return &Breaker{
+		threshold: max(threshold, 1),
+		cooldown:  cooldown,
+	}
+}
+
+// Allow checks whether request can be sent. Returns true if request is probe in half-open state.
+// Result of allowed request should be reported with Success(), Failure() or Cancel()
+func (b *Breaker) Allow() (bool, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case breakerOpen:
+		if elapsed := time.Since(b.openedAt); elapsed < b.cooldown {
+			return false, &CircuitOpenError{RetryAfter: b.cooldown - elapsed}
+		}
+
+		b.state = breakerHalfOpen
+
+		return true, nil
+
+	case breakerHalfOpen:
+		// probe request is already running
+		return false, &CircuitOpenError{}
+	}
+
+	return false, nil
+}
+
+// Success reports successful request
+func (b *Breaker) Success(probe bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if probe || b.state == breakerClosed {
+		b.state = breakerClosed
+		b.failures = 0
+	}
+}
+
+// Failure reports failed request
+func (b *Breaker) Failure(probe bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch {
+	case probe:
+		b.open()
+
+	case b.state == breakerClosed:
+		b.failures++
+		if b.failures >= b.threshold {
+			b.open()
+		}
+	}
+}
+
+// Cancel reports request which finished without result, e.g. cancelled by caller
+func (b *Breaker) Cancel(probe bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	// let next request probe the server
+	if probe && b.state == breakerHalfOpen {
+		b.state = breakerOpen
+		b.openedAt = time.Now().Add(-b.cooldown)
+	}
+}
+
+// open opens breaker. Should be called with b.mu locked
+func (b *Breaker) open() {
+	b.state = breakerOpen
+	b.openedAt = time.Now()
+	b.failures = 0
+}
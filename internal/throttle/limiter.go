@@ -0,0 +1,96 @@
+// Package throttle limits rate and concurrency of requests to Edge TTS server
+package throttle
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrRateLimited is returned in fail fast mode when request rate limit is exceeded
+	ErrRateLimited = errors.New("request rate limit exceeded")
+
+	// ErrTooManyConnections is returned in fail fast mode when all connections are in use
+	ErrTooManyConnections = errors.New("too many simultaneous connections")
+)
+
+// Limiter is token bucket rate limiter
+type Limiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// NewLimiter creates limiter which allows rate requests per second on average and up to burst requests at once
+func NewLimiter(rate float64, burst int) *Limiter {
+	burst = max(burst, 1)
+
+	return &Limiter{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// Allow takes token if it is available
+func (l *Limiter) Allow() bool {
+	return l.reserve(false) == 0
+}
+
+// Wait waits for token
+func (l *Limiter) Wait(ctx context.Context) error {
+	delay := l.reserve(true)
+	if delay == 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		l.Return()
+		return ctx.Err()
+	}
+}
+
+// Return gives back token taken by Allow() or Wait() which was not used to send request
+func (l *Limiter) Return() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.tokens = min(l.tokens+1, l.burst)
+}
+
+// reserve takes token and returns time to wait until it is available. Without wait token is taken only if it
+// is available right now, otherwise -1 is returned
+func (l *Limiter) reserve(wait bool) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.tokens = min(l.tokens+now.Sub(l.last).Seconds()*l.rate, l.burst)
+	l.last = now
+
+	if l.tokens >= 1 {
+		l.tokens--
+		return 0
+	}
+
+	if !wait {
+		return -1
+	}
+
+	// token can be negative which means it is reserved by waiting callers
+	delay := time.Duration((1 - l.tokens) / l.rate * float64(time.Second))
+	l.tokens--
+
+	return delay
+}
@@ -0,0 +1,90 @@
+package throttle
+
+import (
+	"context"
+	"sync"
+)
+
+// Semaphore limits count of simultaneous operations. Limit can be changed at any time
+type Semaphore struct {
+	mu      sync.Mutex
+	limit   int
+	used    int
+	changed chan struct{}
+}
+
+// NewSemaphore creates semaphore which allows up to limit simultaneous operations
+func NewSemaphore(limit int) *Semaphore {
+	return &Semaphore{
+		limit:   max(limit, 1),
+		changed: make(chan struct{}),
+	}
+}
+
+// TryAcquire starts operation if limit is not reached
+func (s *Semaphore) TryAcquire() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.used >= s.limit {
+		return false
+	}
+
+	s.used++
+
+	return true
+}
+
+// Acquire waits until operation can be started
+func (s *Semaphore) Acquire(ctx context.Context) error {
+	for {
+		s.mu.Lock()
+		if s.used < s.limit {
+			s.used++
+			s.mu.Unlock()
+
+			return nil
+		}
+
+		changed := s.changed
+		s.mu.Unlock()
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Release finishes operation
+func (s *Semaphore) Release() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.used--
+	s.notify()
+}
+
+// Limit returns current limit
+func (s *Semaphore) Limit() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.limit
+}
+
+// SetLimit changes limit. Operations already started are not interrupted
+func (s *Semaphore) SetLimit(limit int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.limit = max(limit, 1)
+	s.notify()
+}
+
+// notify wakes up waiting callers. Should be called with s.mu locked
+func (s *Semaphore) notify() {
+	close(s.changed)
+	s.changed = make(chan struct{})
+}
@@ -0,0 +1,163 @@
+package throttle
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func Test_Limiter(t *testing.T) {
+	limiter := NewLimiter(100, 2)
+
+	if !limiter.Allow() || !limiter.Allow() {
+		t.Fatal("expected burst of 2 requests to be allowed")
+	}
+
+	if limiter.Allow() {
+		t.Error("expected request over burst to be rejected")
+	}
+
+	start := time.Now()
+	if err := limiter.Wait(context.Background()); err != nil {
+		t.Fatalf("expected error to be 'nil', but got '%v'", err)
+	}
+
+	if elapsed := time.Since(start); elapsed < 5*time.Millisecond {
+		t.Errorf("expected to wait for token about 10ms, but waited '%v'", elapsed)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	limiter = NewLimiter(0.001, 1)
+	limiter.Allow()
+
+	if err := limiter.Wait(ctx); err == nil {
+		t.Error("expected cancelled wait to fail")
+	}
+
+	limiter.Return()
+	if !limiter.Allow() {
+		t.Error("expected returned token to be available")
+	}
+
+	limiter.Return()
+	limiter.Return()
+	if !limiter.Allow() || limiter.Allow() {
+		t.Error("expected returned tokens not to exceed burst")
+	}
+}
+
+func Test_Semaphore(t *testing.T) {
+	sem := NewSemaphore(1)
+
+	if !sem.TryAcquire() {
+		t.Fatal("expected first acquire to succeed")
+	}
+
+	if sem.TryAcquire() {
+		t.Error("expected acquire over limit to fail")
+	}
+
+	acquired := make(chan error)
+	go func() {
+		acquired <- sem.Acquire(context.Background())
+	}()
+
+	sem.SetLimit(2)
+
+	select {
+	case err := <-acquired:
+		if err != nil {
+			t.Errorf("expected error to be 'nil', but got '%v'", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected acquire to succeed after limit increase")
+	}
+
+	sem.Release()
+	sem.Release()
+
+	if !sem.TryAcquire() {
+		t.Error("expected acquire after release to succeed")
+	}
+}
+
+func Test_Breaker(t *testing.T) {
+	breaker := NewBreaker(2, 20*time.Millisecond)
+
+	for range 2 {
+		if _, err := breaker.Allow(); err != nil {
+			t.Fatalf("expected closed breaker to allow request, but got '%v'", err)
+		}
+
+		breaker.Failure(false)
+	}
+
+	_, err := breaker.Allow()
+
+	var openErr *CircuitOpenError
+	if !errors.As(err, &openErr) || !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected CircuitOpenError after 2 failures, but got '%v'", err)
+	}
+
+	if openErr.RetryAfter <= 0 {
+		t.Errorf("expected positive RetryAfter, but got '%v'", openErr.RetryAfter)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+
+	probe, err := breaker.Allow()
+	if err != nil || !probe {
+		t.Fatalf("expected probe after cooldown, but got '%v', '%v'", probe, err)
+	}
+
+	if _, err := breaker.Allow(); err == nil {
+		t.Error("expected only one probe in half-open state")
+	}
+
+	breaker.Failure(probe)
+	if _, err := breaker.Allow(); err == nil {
+		t.Error("expected failed probe to open breaker again")
+	}
+
+	time.Sleep(30 * time.Millisecond)
+
+	probe, _ = breaker.Allow()
+	breaker.Success(probe)
+
+	if _, err := breaker.Allow(); err != nil {
+		t.Errorf("expected successful probe to close breaker, but got '%v'", err)
+	}
+}
+
+func Test_Adaptive(t *testing.T) {
+	sem := NewSemaphore(8)
+	adaptive := NewAdaptive(sem)
+
+	adaptive.Throttled()
+	adaptive.Throttled()
+
+	if sem.Limit() != 2 {
+		t.Fatalf("expected limit to be halved twice to 2, but got '%v'", sem.Limit())
+	}
+
+	adaptive.Success()
+	if sem.Limit() != 2 {
+		t.Errorf("expected limit to grow after 2 successes, but got '%v' after one", sem.Limit())
+	}
+
+	adaptive.Success()
+	if sem.Limit() != 3 {
+		t.Errorf("expected limit to be '3', but got '%v'", sem.Limit())
+	}
+
+	for range 100 {
+		adaptive.Success()
+	}
+
+	if sem.Limit() != 8 {
+		t.Errorf("expected limit to recover to maximum '8', but got '%v'", sem.Limit())
+	}
+}
@@ -14,6 +14,12 @@ type Args struct {
 
 	// Rate delta, e.g. "+10%" or "-20%"
 	Rate string
+
+	// Pitch delta, e.g. "+5Hz" or "-10Hz"
+	Pitch string
+
+	// Text is SSML document which is sent as is, Voice, Volume, Rate and Pitch are ignored
+	SSML bool
 }
 
 func (args *Args) getVoice() (string, error) {
@@ -64,3 +70,16 @@ func (args *Args) getVolume() (string, error) {
 
 	return args.Volume, nil
 }
+
+func (args *Args) getPitch() (string, error) {
+	// default value
+	if args.Pitch == "" {
+		return "+0Hz", nil
+	}
+
+	if !regexp.MustCompile(`^[+-]\d+Hz$`).MatchString(args.Pitch) {
+		return "", fmt.Errorf("pitch should have format '+12Hz' or '-34Hz'")
+	}
+
+	return args.Pitch, nil
+}
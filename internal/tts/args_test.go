@@ -1,6 +1,7 @@
 package tts
 
 import (
+	"strings"
 	"testing"
 )
 
@@ -73,3 +74,81 @@ func Test_getVoice(t *testing.T) {
 		})
 	}
 }
+
+func Test_getPitch(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    Args
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "default pitch",
+			args:    Args{},
+			want:    "+0Hz",
+			wantErr: false,
+		},
+		{
+			name: "higher pitch",
+			args: Args{
+				Pitch: "+5Hz",
+			},
+			want:    "+5Hz",
+			wantErr: false,
+		},
+		{
+			name: "lower pitch",
+			args: Args{
+				Pitch: "-10Hz",
+			},
+			want:    "-10Hz",
+			wantErr: false,
+		},
+		{
+			name: "pitch without sign",
+			args: Args{
+				Pitch: "5Hz",
+			},
+			want:    "",
+			wantErr: true,
+		},
+		{
+			name: "pitch in percent",
+			args: Args{
+				Pitch: "+5%",
+			},
+			want:    "",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pitch, err := tt.args.getPitch()
+
+			gotErr := err != nil
+			if gotErr != tt.wantErr {
+				if tt.wantErr {
+					t.Error("expected to get error, but got nothing")
+				} else {
+					t.Errorf("expected error to be 'nil', but got '%v", err)
+				}
+			}
+
+			if pitch != tt.want {
+				t.Errorf("expected pitch to be '%v', but got '%v'", tt.want, pitch)
+			}
+		})
+	}
+}
+
+func Test_mkssmlPitch(t *testing.T) {
+	params, err := getSpeechParams("Hello", Args{Voice: "en-US-AvaNeural", Pitch: "-10Hz"}, OutputFormatMp3)
+	if err != nil {
+		t.Fatalf("expected error to be 'nil', but got '%v'", err)
+	}
+
+	if ssml := params.ssml(); !strings.Contains(ssml, "<prosody pitch='-10Hz' rate='+0%' volume='+0%'>Hello</prosody>") {
+		t.Errorf("expected SSML to contain pitch, but got '%v'", ssml)
+	}
+}
@@ -0,0 +1,153 @@
+package tts
+
+import (
+	"time"
+)
+
+const mp3HeaderSize = 4
+
+var (
+	// bitrates of MPEG layer III in kbps by bitrate index
+	mp3BitratesV1 = [16]int{0, 32, 40, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320, 0}
+	mp3BitratesV2 = [16]int{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160, 0}
+
+	// sample rates in hz by sample rate index
+	mp3SampleRatesV1  = [4]int{44_100, 48_000, 32_000, 0}
+	mp3SampleRatesV2  = [4]int{22_050, 24_000, 16_000, 0}
+	mp3SampleRatesV25 = [4]int{11_025, 12_000, 8_000, 0}
+)
+
+// AudioTimer calculates playback duration of sound data which comes in pieces
+type AudioTimer struct {
+	// count of bytes per second for formats with constant byte rate
+	bytesPerSecond int
+
+	// total size and duration of sound data passed to timer
+	size     int64
+	duration time.Duration
+
+	// mp3 parsing state: parsed formats count duration by frames
+	mp3    bool
+	skip   int
+	header []byte
+}
+
+// NewAudioTimer creates timer for sound data of format. Duration of mp3 is counted by frame headers,
+// duration of other formats is counted by byte rate.
+func NewAudioTimer(format OutputFormat) *AudioTimer {
+	wireFormat := format.WireFormat()
+
+	return &AudioTimer{
+		bytesPerSecond: wireFormat.BytesPerSecond(),
+		mp3:            wireFormat.Info().Codec == "mp3",
+		header:         make([]byte, 0, mp3HeaderSize),
+	}
+}
+
+// Duration returns playback duration of next piece of sound data
+func (t *AudioTimer) Duration(data []byte) time.Duration {
+	if t.mp3 {
+		return t.mp3Duration(data)
+	}
+
+	// count duration from total size to avoid accumulation of rounding errors
+	t.size += int64(len(data))
+	total := time.Duration(t.size * int64(time.Second) / int64(t.bytesPerSecond))
+
+	duration := total - t.duration
+	t.duration = total
+
+	return duration
+}
+
+// Total returns playback duration of all sound data passed to timer
+func (t *AudioTimer) Total() time.Duration {
+	return t.duration
+}
+
+func (t *AudioTimer) mp3Duration(data []byte) time.Duration {
+	var duration time.Duration
+
+	for len(data) > 0 {
+		// skip frame body
+		if t.skip > 0 {
+			n := min(t.skip, len(data))
+			t.skip -= n
+			data = data[n:]
+
+			continue
+		}
+
+		// collect frame header which can be split between pieces
+		n := min(mp3HeaderSize-len(t.header), len(data))
+		t.header = append(t.header, data[:n]...)
+		data = data[n:]
+
+		if len(t.header) < mp3HeaderSize {
+			break
+		}
+
+		frameSize, frameDuration, ok := ParseMp3FrameHeader(t.header)
+		if !ok {
+			// lost synchronization, search for next frame header byte by byte
+			copy(t.header, t.header[1:])
+			t.header = t.header[:mp3HeaderSize-1]
+
+			continue
+		}
+
+		duration += frameDuration
+		t.skip = frameSize - mp3HeaderSize
+		t.header = t.header[:0]
+	}
+
+	t.duration += duration
+
+	return duration
+}
+
+// ParseMp3FrameHeader parses MPEG layer III frame header and returns frame size in bytes and its duration
+func ParseMp3FrameHeader(header []byte) (int, time.Duration, bool) {
+	if len(header) < mp3HeaderSize || header[0] != 0xFF || header[1]&0xE0 != 0xE0 {
+		return 0, 0, false
+	}
+
+	version := (header[1] >> 3) & 0x03
+	layer := (header[1] >> 1) & 0x03
+	bitrateIndex := header[2] >> 4
+	sampleRateIndex := (header[2] >> 2) & 0x03
+	padding := int((header[2] >> 1) & 0x01)
+
+	// layer III only
+	if layer != 0x01 {
+		return 0, 0, false
+	}
+
+	var bitrate, sampleRate, samples int
+
+	switch version {
+	case 0x03: // MPEG 1
+		bitrate = mp3BitratesV1[bitrateIndex]
+		sampleRate = mp3SampleRatesV1[sampleRateIndex]
+		samples = 1152
+	case 0x02: // MPEG 2
+		bitrate = mp3BitratesV2[bitrateIndex]
+		sampleRate = mp3SampleRatesV2[sampleRateIndex]
+		samples = 576
+	case 0x00: // MPEG 2.5
+		bitrate = mp3BitratesV2[bitrateIndex]
+		sampleRate = mp3SampleRatesV25[sampleRateIndex]
+		samples = 576
+	default:
+		return 0, 0, false
+	}
+
+	if bitrate == 0 || sampleRate == 0 {
+		return 0, 0, false
+	}
+
+	frameSize := samples/8*bitrate*1000/sampleRate + padding
+	duration := time.Duration(samples) * time.Second / time.Duration(sampleRate)
+
+	return frameSize, duration, true
+}
@@ -0,0 +1,65 @@
+package tts
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_AudioTimer(t *testing.T) {
+	// MPEG 2 layer III, 48 kbps, 24 khz, no padding: 144 bytes and 24 ms per frame
+	frame := make([]byte, 144)
+	copy(frame, []byte{0xFF, 0xF3, 0x64, 0xC4})
+
+	stream := make([]byte, 0, len(frame)*10)
+	for range 10 {
+		stream = append(stream, frame...)
+	}
+
+	tests := []struct {
+		name   string
+		format OutputFormat
+		data   []byte
+		pieces int
+		want   time.Duration
+	}{
+		{
+			name:   "mp3 frames in one piece",
+			format: OutputFormatMp3,
+			data:   stream,
+			pieces: 1,
+			want:   240 * time.Millisecond,
+		},
+		{
+			name:   "mp3 frames split inside headers",
+			format: OutputFormatMp3,
+			data:   stream,
+			pieces: 7,
+			want:   240 * time.Millisecond,
+		},
+		{
+			name:   "raw pcm",
+			format: OutputFormatRaw22050,
+			data:   make([]byte, 22_050*2),
+			pieces: 3,
+			want:   time.Second,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			timer := NewAudioTimer(tt.format)
+
+			var total time.Duration
+			size := len(tt.data)/tt.pieces + 1
+			for data := tt.data; len(data) > 0; {
+				n := min(size, len(data))
+				total += timer.Duration(data[:n])
+				data = data[n:]
+			}
+
+			if total != tt.want || timer.Total() != tt.want {
+				t.Errorf("expected duration to be '%v', but got '%v' (total '%v')", tt.want, total, timer.Total())
+			}
+		})
+	}
+}
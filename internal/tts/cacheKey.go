@@ -0,0 +1,33 @@
+package tts
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// CacheKey returns hash of all parameters which affect synthesized sound:
+// text, voice, rate, volume, pitch, output format and resulting SSML
+func CacheKey(text string, args Args, format OutputFormat) (string, error) {
+	params, err := getSpeechParams(text, args, format)
+	if err != nil {
+		return "", err
+	}
+
+	fields := []string{
+		params.text,
+		params.voice,
+		params.rate,
+		params.volume,
+		params.pitch,
+		params.format,
+		params.ssml(),
+	}
+
+	hash := sha256.New()
+	for _, field := range fields {
+		hash.Write([]byte(field))
+		hash.Write([]byte{0})
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
@@ -0,0 +1,13 @@
+package tts
+
+import "time"
+
+// Conn is websocket connection used by session. It is implemented by *websocket.Conn,
+// by recording wrapper and by replay of recorded session
+type Conn interface {
+	ReadMessage() (int, []byte, error)
+	WriteMessage(messageType int, data []byte) error
+	WriteControl(messageType int, data []byte, deadline time.Time) error
+	SetReadDeadline(t time.Time) error
+	Close() error
+}
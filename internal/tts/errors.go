@@ -0,0 +1,66 @@
+package tts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+
+	"github.com/gorilla/websocket"
+)
+
+// HandshakeError is returned when Edge TTS server rejects websocket handshake
+type HandshakeError struct {
+	// HTTP status code of server response, 0 if there is no response
+	StatusCode int
+
+	Err error
+}
+
+func (e *HandshakeError) Error() string {
+	if e.StatusCode != 0 {
+		return fmt.Sprintf("websocket handshake failed with status %d: %v", e.StatusCode, e.Err)
+	}
+
+	return fmt.Sprintf("websocket handshake failed: %v", e.Err)
+}
+
+func (e *HandshakeError) Unwrap() error {
+	return e.Err
+}
+
+// ClassifyError reports whether error is failure of Edge TTS server or network
+// and whether it is signal that server throttles requests
+func ClassifyError(err error) (bool, bool) {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false, false
+	}
+
+	if errors.Is(err, ErrHandshakeTimeout) || errors.Is(err, ErrFirstByteTimeout) || errors.Is(err, ErrIdleTimeout) {
+		return true, false
+	}
+
+	var handshakeErr *HandshakeError
+	if errors.As(err, &handshakeErr) {
+		throttled := handshakeErr.StatusCode == http.StatusTooManyRequests ||
+			handshakeErr.StatusCode == http.StatusForbidden
+
+		return true, throttled
+	}
+
+	var closeErr *websocket.CloseError
+	if errors.As(err, &closeErr) {
+		throttled := closeErr.Code == websocket.ClosePolicyViolation ||
+			closeErr.Code == websocket.CloseTryAgainLater
+
+		return closeErr.Code != websocket.CloseNormalClosure, throttled
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true, false
+	}
+
+	return false, false
+}
@@ -0,0 +1,41 @@
+package tts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/gorilla/websocket"
+)
+
+func Test_ClassifyError(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		failure   bool
+		throttled bool
+	}{
+		{"nil", nil, false, false},
+		{"cancelled", fmt.Errorf("read: %w", context.Canceled), false, false},
+		{"too many requests", &HandshakeError{StatusCode: 429, Err: websocket.ErrBadHandshake}, true, true},
+		{"forbidden", &HandshakeError{StatusCode: 403, Err: websocket.ErrBadHandshake}, true, true},
+		{"server error", &HandshakeError{StatusCode: 500, Err: websocket.ErrBadHandshake}, true, false},
+		{"abnormal close", &websocket.CloseError{Code: websocket.CloseAbnormalClosure}, true, false},
+		{"try again later", &websocket.CloseError{Code: websocket.CloseTryAgainLater}, true, true},
+		{"normal close", &websocket.CloseError{Code: websocket.CloseNormalClosure}, false, false},
+		{"idle timeout", ErrIdleTimeout, true, false},
+		{"network", &net.OpError{Op: "dial", Err: errors.New("connection refused")}, true, false},
+		{"other", errors.New("invalid voice"), false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			failure, throttled := ClassifyError(tt.err)
+
+			if failure != tt.failure || throttled != tt.throttled {
+				t.Errorf("expected '%v, %v', but got '%v, %v'", tt.failure, tt.throttled, failure, throttled)
+			}
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package tts
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// Message is websocket message of Edge TTS protocol: text message with headers and body
+// or binary message with headers and audio data
+type Message struct {
+	// Time when message was sent or received
+	Time time.Time
+
+	// True for messages sent by client, false for messages received from server
+	Outgoing bool
+
+	// True for binary messages, false for text messages
+	Binary bool
+
+	// Value of Path header, e.g. "turn.start", "audio.metadata", "response" or "audio"
+	Path string
+
+	// Headers in order they appear in message
+	Headers []Header
+
+	// Body of message: JSON, SSML or audio data. If message can't be parsed it contains the whole message
+	Body []byte
+}
+
+// Header is header of protocol message
+type Header struct {
+	Name  string
+	Value string
+}
+
+// Header returns value of header by case insensitive name or empty string if there is no such header
+func (m Message) Header(name string) string {
+	for _, header := range m.Headers {
+		if strings.EqualFold(header.Name, name) {
+			return header.Value
+		}
+	}
+
+	return ""
+}
+
+// RequestID returns value of X-RequestId header
+func (m Message) RequestID() string {
+	return m.Header("X-RequestId")
+}
+
+// ContentType returns value of Content-Type header
+func (m Message) ContentType() string {
+	return m.Header("Content-Type")
+}
+
+// ParseMessage parses raw websocket message. If message is malformed, returned message contains it as Body
+func ParseMessage(isBinary bool, data []byte) (Message, error) {
+	message := Message{
+		Binary: isBinary,
+		Body:   data,
+	}
+
+	var headers []byte
+
+	if isBinary {
+		if len(data) < 2 {
+			return message, fmt.Errorf("binary message lacks header length")
+		}
+
+		headerLength := int(binary.BigEndian.Uint16(data[:2]))
+		if len(data) < headerLength+2 {
+			return message, fmt.Errorf("binary message lacks audio data")
+		}
+
+		headers = data[2 : headerLength+2]
+		message.Body = data[headerLength+2:]
+	} else {
+		var ok bool
+
+		headers, message.Body, ok = bytes.Cut(data, doubleEol)
+		if !ok {
+			message.Body = data
+			return message, fmt.Errorf("message lacks headers separator: %q", data)
+		}
+	}
+
+	for line := range bytes.SplitSeq(headers, eol) {
+		name, value, ok := bytes.Cut(line, []byte(":"))
+		if !ok {
+			continue
+		}
+
+		message.Headers = append(message.Headers, Header{
+			Name:  string(bytes.TrimSpace(name)),
+			Value: string(bytes.TrimSpace(value)),
+		})
+	}
+
+	message.Path = message.Header("Path")
+
+	return message, nil
+}
+
+// notifyMessage passes raw websocket message to observer if it is set
+func notifyMessage(onMessage func(Message), outgoing bool, messageType int, data []byte) {
+	if onMessage == nil {
+		return
+	}
+
+	message, _ := ParseMessage(messageType == websocket.BinaryMessage, data)
+	message.Time = time.Now()
+	message.Outgoing = outgoing
+
+	onMessage(message)
+}
+
+// writeMessage sends text message and passes it to observer
+func writeMessage(conn Conn, data []byte, onMessage func(Message)) error {
+	if err := conn.WriteMessage(websocket.TextMessage, data); err != nil {
+		return err
+	}
+
+	notifyMessage(onMessage, true, websocket.TextMessage, data)
+
+	return nil
+}
@@ -0,0 +1,66 @@
+package tts
+
+import (
+	"bytes"
+	"testing"
+)
+
+func Test_ParseMessage(t *testing.T) {
+	tests := []struct {
+		name        string
+		binary      bool
+		data        []byte
+		path        string
+		requestID   string
+		contentType string
+		body        []byte
+		wantErr     bool
+	}{
+		{
+			name:        "text",
+			data:        []byte("X-RequestId:abc\r\nContent-Type:application/json; charset=utf-8\r\nPath:response\r\n\r\n{}"),
+			path:        "response",
+			requestID:   "abc",
+			contentType: "application/json; charset=utf-8",
+			body:        []byte("{}"),
+		},
+		{
+			name:        "binary",
+			binary:      true,
+			data:        append([]byte{0, 54}, []byte("X-RequestId:abc\r\nContent-Type:audio/mpeg\r\nPath:audio\r\nsound")...),
+			path:        "audio",
+			requestID:   "abc",
+			contentType: "audio/mpeg",
+			body:        []byte("sound"),
+		},
+		{
+			name:    "text without separator",
+			data:    []byte("Path:response"),
+			body:    []byte("Path:response"),
+			wantErr: true,
+		},
+		{
+			name:    "binary without audio",
+			binary:  true,
+			data:    []byte{0, 100, 1},
+			body:    []byte{0, 100, 1},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			message, err := ParseMessage(tt.binary, tt.data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error to be '%v' but got '%v'", tt.wantErr, err)
+			}
+
+			if message.Path != tt.path || message.RequestID() != tt.requestID || message.ContentType() != tt.contentType {
+				t.Errorf("expected headers '%s', '%s', '%s' but got '%+v'", tt.path, tt.requestID, tt.contentType, message.Headers)
+			}
+
+			if !bytes.Equal(message.Body, tt.body) {
+				t.Errorf("expected body to be '%s' but got '%s'", tt.body, message.Body)
+			}
+		})
+	}
+}
@@ -1,28 +1,319 @@
 package tts
 
-type OutputFormat int8
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// OutputFormat is sound data output format string as Edge TTS server accepts it.
+// Any string can be converted to OutputFormat to pass it to server verbatim.
+type OutputFormat string
 
 const (
-	OutputFormatMp3 OutputFormat = iota
-	OutputFormatWebm
-	OutputFormatOgg
-	OutputFormatRaw22050
-	OutputFormatRaw44100
+	// OutputFormatAuto lets SaveToFile infer format from file extension, in other places it means mp3
+	OutputFormatAuto OutputFormat = ""
+
+	OutputFormatMp3               OutputFormat = "audio-24khz-48kbitrate-mono-mp3"
+	OutputFormatMp3At16kHz32kbps  OutputFormat = "audio-16khz-32kbitrate-mono-mp3"
+	OutputFormatMp3At16kHz64kbps  OutputFormat = "audio-16khz-64kbitrate-mono-mp3"
+	OutputFormatMp3At16kHz128kbps OutputFormat = "audio-16khz-128kbitrate-mono-mp3"
+	OutputFormatMp3At24kHz96kbps  OutputFormat = "audio-24khz-96kbitrate-mono-mp3"
+	OutputFormatMp3At24kHz160kbps OutputFormat = "audio-24khz-160kbitrate-mono-mp3"
+	OutputFormatMp3At48kHz96kbps  OutputFormat = "audio-48khz-96kbitrate-mono-mp3"
+	OutputFormatMp3At48kHz192kbps OutputFormat = "audio-48khz-192kbitrate-mono-mp3"
+
+	OutputFormatWebm      OutputFormat = "webm-24khz-16bit-24kbps-mono-opus"
+	OutputFormatWebm16000 OutputFormat = "webm-16khz-16bit-mono-opus"
+	OutputFormatWebm24000 OutputFormat = "webm-24khz-16bit-mono-opus"
+
+	OutputFormatOgg      OutputFormat = "ogg-24khz-16bit-mono-opus"
+	OutputFormatOgg16000 OutputFormat = "ogg-16khz-16bit-mono-opus"
+	OutputFormatOgg48000 OutputFormat = "ogg-48khz-16bit-mono-opus"
+
+	OutputFormatRaw8000  OutputFormat = "raw-8khz-16bit-mono-pcm"
+	OutputFormatRaw16000 OutputFormat = "raw-16khz-16bit-mono-pcm"
+	OutputFormatRaw22050 OutputFormat = "raw-22050hz-16bit-mono-pcm"
+	OutputFormatRaw24000 OutputFormat = "raw-24khz-16bit-mono-pcm"
+	OutputFormatRaw44100 OutputFormat = "raw-44100hz-16bit-mono-pcm"
+	OutputFormatRaw48000 OutputFormat = "raw-48khz-16bit-mono-pcm"
+
+	OutputFormatMulaw8000 OutputFormat = "raw-8khz-8bit-mono-mulaw"
+	OutputFormatAlaw8000  OutputFormat = "raw-8khz-8bit-mono-alaw"
+
+	// WAV formats are requested from server as raw PCM and wrapped in RIFF header by this library
+	OutputFormatWav8000  OutputFormat = "riff-8khz-16bit-mono-pcm"
+	OutputFormatWav16000 OutputFormat = "riff-16khz-16bit-mono-pcm"
+	OutputFormatWav22050 OutputFormat = "riff-22050hz-16bit-mono-pcm"
+	OutputFormatWav24000 OutputFormat = "riff-24khz-16bit-mono-pcm"
+	OutputFormatWav44100 OutputFormat = "riff-44100hz-16bit-mono-pcm"
+	OutputFormatWav48000 OutputFormat = "riff-48khz-16bit-mono-pcm"
+)
+
+var outputFormats = []OutputFormat{
+	OutputFormatMp3,
+	OutputFormatMp3At16kHz32kbps,
+	OutputFormatMp3At16kHz64kbps,
+	OutputFormatMp3At16kHz128kbps,
+	OutputFormatMp3At24kHz96kbps,
+	OutputFormatMp3At24kHz160kbps,
+	OutputFormatMp3At48kHz96kbps,
+	OutputFormatMp3At48kHz192kbps,
+	OutputFormatWebm,
+	OutputFormatWebm16000,
+	OutputFormatWebm24000,
+	OutputFormatOgg,
+	OutputFormatOgg16000,
+	OutputFormatOgg48000,
+	OutputFormatRaw8000,
+	OutputFormatRaw16000,
+	OutputFormatRaw22050,
+	OutputFormatRaw24000,
+	OutputFormatRaw44100,
+	OutputFormatRaw48000,
+	OutputFormatMulaw8000,
+	OutputFormatAlaw8000,
+	OutputFormatWav8000,
+	OutputFormatWav16000,
+	OutputFormatWav22050,
+	OutputFormatWav24000,
+	OutputFormatWav44100,
+	OutputFormatWav48000,
+}
+
+// OutputFormatInfo describes sound data of output format
+type OutputFormatInfo struct {
+	// Format string as Edge TTS server accepts it
+	Format OutputFormat
+
+	// MIME type to use in Content-Type header, e.g. "audio/mpeg"
+	MIMEType string
+
+	// File extension with leading dot, e.g. ".mp3"
+	Extension string
+
+	// Codec name: "mp3", "opus", "pcm", "mulaw" or "alaw"
+	Codec string
+
+	// Sample rate in hz
+	SampleRate int
+
+	// Bits per sample
+	BitDepth int
+
+	// Count of channels
+	Channels int
+
+	// Bits per second, exact for raw and constant bitrate formats
+	Bitrate int
+
+	// True if sound data is wrapped in container like webm or ogg, false for raw samples or mp3 frames
+	Container bool
+}
+
+// extensionFormats maps file extensions to default output format with such extension
+var extensionFormats = map[string]OutputFormat{
+	".mp3":  OutputFormatMp3,
+	".webm": OutputFormatWebm,
+	".ogg":  OutputFormatOgg,
+	".opus": OutputFormatOgg,
+	".pcm":  OutputFormatRaw24000,
+	".raw":  OutputFormatRaw24000,
+	".ulaw": OutputFormatMulaw8000,
+	".alaw": OutputFormatAlaw8000,
+	".wav":  OutputFormatWav24000,
+}
+
+var (
+	sampleRateRegexp = regexp.MustCompile(`-(\d+)(k?)hz-`)
+	bitrateRegexp    = regexp.MustCompile(`-(\d+)(?:kbitrate|kbps)-`)
+	bitDepthRegexp   = regexp.MustCompile(`-(\d+)bit-`)
+	rawCodecRegexp   = regexp.MustCompile(`-(pcm|mulaw|alaw)$`)
 )
 
+// OutputFormats returns all output formats known to be supported by Edge TTS server
+func OutputFormats() []OutputFormat {
+	result := make([]OutputFormat, len(outputFormats))
+	copy(result, outputFormats)
+
+	return result
+}
+
+// String returns format as Edge TTS server accepts it. Empty format is mp3 24khz 48k bitrate
 func (f OutputFormat) String() string {
-	switch f {
-	case OutputFormatWebm:
-		return "webm-24khz-16bit-24kbps-mono-opus"
-	case OutputFormatOgg:
-		return "ogg-24khz-16bit-mono-opus"
-	case OutputFormatRaw22050:
-		return "raw-22050hz-16bit-mono-pcm"
-	case OutputFormatRaw44100:
-		return "raw-44100hz-16bit-mono-pcm"
-	case OutputFormatMp3:
-		fallthrough
+	if f == "" {
+		return string(OutputFormatMp3)
+	}
+
+	return string(f)
+}
+
+// IsWav reports whether sound data is raw PCM wrapped in RIFF header by this library
+func (f OutputFormat) IsWav() bool {
+	return strings.HasPrefix(string(f), "riff-")
+}
+
+// IsContainer reports whether sound data is wrapped in webm or ogg container. Such sound data of several
+// responses can't be joined into one stream byte by byte
+func (f OutputFormat) IsContainer() bool {
+	return strings.HasPrefix(string(f), "webm-") || strings.HasPrefix(string(f), "ogg-")
+}
+
+// WireFormat returns format to request from Edge TTS server: raw PCM for WAV formats and format itself otherwise
+func (f OutputFormat) WireFormat() OutputFormat {
+	if f.IsWav() {
+		return OutputFormat("raw-" + strings.TrimPrefix(string(f), "riff-"))
+	}
+
+	return f
+}
+
+// BytesPerSecond returns approximate size of one second of sound data.
+// It is exact for raw formats and constant bitrate formats.
+func (f OutputFormat) BytesPerSecond() int {
+	format := f.String()
+
+	// compressed formats with explicit bitrate, e.g. "audio-24khz-48kbitrate-mono-mp3"
+	if match := bitrateRegexp.FindStringSubmatch(format); match != nil {
+		kbps, _ := strconv.Atoi(match[1])
+		return kbps * 1000 / 8
+	}
+
+	// uncompressed formats, e.g. "raw-22050hz-16bit-mono-pcm"
+	sampleRate := parseSampleRate(format)
+	bitDepth := parseBitDepth(format)
+	if isUncompressed(format) && sampleRate > 0 && bitDepth > 0 {
+		return sampleRate * bitDepth / 8
+	}
+
+	// opus without explicit bitrate
+	return 48_000 / 8
+}
+
+func parseSampleRate(format string) int {
+	match := sampleRateRegexp.FindStringSubmatch(format)
+	if match == nil {
+		return 0
+	}
+
+	rate, _ := strconv.Atoi(match[1])
+	if match[2] == "k" {
+		rate *= 1000
+	}
+
+	return rate
+}
+
+func parseBitDepth(format string) int {
+	match := bitDepthRegexp.FindStringSubmatch(format)
+	if match == nil {
+		return 0
+	}
+
+	depth, _ := strconv.Atoi(match[1])
+
+	return depth
+}
+
+func isUncompressed(format string) bool {
+	return rawCodecRegexp.MatchString(format)
+}
+
+// ParseOutputFormat gets output format from name of known format like "raw-24khz-16bit-mono-pcm"
+// or from file extension like ".mp3" or "mp3". Convert string to OutputFormat directly to pass other formats verbatim
+func ParseOutputFormat(str string) (OutputFormat, error) {
+	str = strings.ToLower(strings.TrimSpace(str))
+
+	if format, ok := extensionFormats[str]; ok {
+		return format, nil
+	}
+
+	if format, ok := extensionFormats["."+str]; ok {
+		return format, nil
+	}
+
+	if slices.Contains(outputFormats, OutputFormat(str)) {
+		return OutputFormat(str), nil
+	}
+
+	return OutputFormatAuto, fmt.Errorf("unknown output format '%s'", str)
+}
+
+// FormatFromFilename gets output format from file extension. Returns false for unknown extensions
+func FormatFromFilename(filename string) (OutputFormat, bool) {
+	format, ok := extensionFormats[strings.ToLower(filepath.Ext(filename))]
+	return format, ok
+}
+
+// Info returns description of sound data of output format
+func (f OutputFormat) Info() OutputFormatInfo {
+	format := f.String()
+
+	info := OutputFormatInfo{
+		Format:     OutputFormat(format),
+		SampleRate: parseSampleRate(format),
+		BitDepth:   parseBitDepth(format),
+		Channels:   1,
+		Bitrate:    f.BytesPerSecond() * 8,
+	}
+
+	if strings.Contains(format, "-stereo-") {
+		info.Channels = 2
+	}
+
+	if i := strings.LastIndex(format, "-"); i >= 0 {
+		info.Codec = format[i+1:]
+	}
+
+	container, _, _ := strings.Cut(format, "-")
+
+	switch container {
+	case "audio":
+		info.MIMEType = "audio/mpeg"
+		info.Extension = ".mp3"
+	case "webm":
+		info.MIMEType = "audio/webm"
+		info.Extension = ".webm"
+		info.Container = true
+	case "ogg":
+		info.MIMEType = "audio/ogg"
+		info.Extension = ".ogg"
+		info.Container = true
+	case "riff":
+		info.MIMEType = "audio/wav"
+		info.Extension = ".wav"
+		info.Container = true
+	case "raw":
+		switch info.Codec {
+		case "mulaw":
+			info.MIMEType = "audio/basic"
+			info.Extension = ".ulaw"
+		case "alaw":
+			info.MIMEType = "audio/x-alaw-basic"
+			info.Extension = ".alaw"
+		default:
+			info.MIMEType = "audio/L" + strconv.Itoa(info.BitDepth) +
+				";rate=" + strconv.Itoa(info.SampleRate) +
+				";channels=" + strconv.Itoa(info.Channels)
+			info.Extension = ".pcm"
+		}
 	default:
-		return "audio-24khz-48kbitrate-mono-mp3"
+		info.MIMEType = "application/octet-stream"
+		info.Extension = ".bin"
 	}
+
+	return info
+}
+
+// MIMEType returns MIME type to use in Content-Type header, e.g. "audio/mpeg"
+func (f OutputFormat) MIMEType() string {
+	return f.Info().MIMEType
+}
+
+// Extension returns file extension with leading dot, e.g. ".mp3"
+func (f OutputFormat) Extension() string {
+	return f.Info().Extension
 }
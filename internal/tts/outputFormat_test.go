@@ -0,0 +1,199 @@
+package tts
+
+import (
+	"testing"
+)
+
+func Test_BytesPerSecond(t *testing.T) {
+	tests := []struct {
+		name   string
+		format OutputFormat
+		want   int
+	}{
+		{
+			name:   "default format",
+			format: "",
+			want:   48_000 / 8,
+		},
+		{
+			name:   "mp3 with bitrate",
+			format: OutputFormatMp3At48kHz192kbps,
+			want:   192_000 / 8,
+		},
+		{
+			name:   "webm with bitrate",
+			format: OutputFormatWebm,
+			want:   24_000 / 8,
+		},
+		{
+			name:   "raw pcm in hz",
+			format: OutputFormatRaw22050,
+			want:   22_050 * 2,
+		},
+		{
+			name:   "raw pcm in khz",
+			format: OutputFormatRaw16000,
+			want:   16_000 * 2,
+		},
+		{
+			name:   "mu-law",
+			format: OutputFormatMulaw8000,
+			want:   8_000,
+		},
+		{
+			name:   "verbatim format",
+			format: OutputFormat("raw-32khz-16bit-mono-pcm"),
+			want:   32_000 * 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.format.BytesPerSecond(); got != tt.want {
+				t.Errorf("expected bytes per second to be '%v', but got '%v'", tt.want, got)
+			}
+		})
+	}
+}
+
+func Test_ParseOutputFormat(t *testing.T) {
+	tests := []struct {
+		name    string
+		str     string
+		want    OutputFormat
+		wantErr bool
+	}{
+		{
+			name: "extension with dot",
+			str:  ".mp3",
+			want: OutputFormatMp3,
+		},
+		{
+			name: "extension without dot",
+			str:  "OGG",
+			want: OutputFormatOgg,
+		},
+		{
+			name: "known format",
+			str:  "raw-44100hz-16bit-mono-pcm",
+			want: OutputFormatRaw44100,
+		},
+		{
+			name:    "unlisted format",
+			str:     "raw-32khz-16bit-mono-pcm",
+			want:    OutputFormatAuto,
+			wantErr: true,
+		},
+		{
+			name:    "unknown format",
+			str:     "flac",
+			want:    OutputFormatAuto,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			format, err := ParseOutputFormat(tt.str)
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("unexpected error '%v'", err)
+			}
+
+			if format != tt.want {
+				t.Errorf("expected format to be '%v', but got '%v'", tt.want, format)
+			}
+		})
+	}
+}
+
+func Test_FormatFromFilename(t *testing.T) {
+	tests := []struct {
+		name     string
+		filename string
+		want     OutputFormat
+		wantOK   bool
+	}{
+		{
+			name:     "known extension",
+			filename: "/tmp/speech.WAV",
+			want:     OutputFormatWav24000,
+			wantOK:   true,
+		},
+		{
+			name:     "unknown extension",
+			filename: "speech.txt",
+			want:     OutputFormatAuto,
+		},
+		{
+			name:     "no extension",
+			filename: "speech",
+			want:     OutputFormatAuto,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			format, ok := FormatFromFilename(tt.filename)
+
+			if ok != tt.wantOK {
+				t.Errorf("expected ok to be '%v', but got '%v'", tt.wantOK, ok)
+			}
+
+			if format != tt.want {
+				t.Errorf("expected format to be '%v', but got '%v'", tt.want, format)
+			}
+		})
+	}
+}
+
+func Test_IsContainer(t *testing.T) {
+	tests := []struct {
+		format OutputFormat
+		want   bool
+	}{
+		{OutputFormatWebm, true},
+		{OutputFormatWebm16000, true},
+		{OutputFormatOgg, true},
+		{OutputFormatOgg48000, true},
+		{OutputFormatMp3, false},
+		{OutputFormatRaw24000, false},
+		{OutputFormatWav24000, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format.String(), func(t *testing.T) {
+			if got := tt.format.IsContainer(); got != tt.want {
+				t.Errorf("expected IsContainer() to be '%v', but got '%v'", tt.want, got)
+			}
+		})
+	}
+}
+
+func Test_Info(t *testing.T) {
+	info := OutputFormatRaw22050.Info()
+
+	want := OutputFormatInfo{
+		Format:     OutputFormatRaw22050,
+		MIMEType:   "audio/L16;rate=22050;channels=1",
+		Extension:  ".pcm",
+		Codec:      "pcm",
+		SampleRate: 22_050,
+		BitDepth:   16,
+		Channels:   1,
+		Bitrate:    22_050 * 16,
+		Container:  false,
+	}
+
+	if info != want {
+		t.Errorf("expected info to be '%+v', but got '%+v'", want, info)
+	}
+
+	if ext := OutputFormatWebm.Extension(); ext != ".webm" {
+		t.Errorf("expected extension to be '.webm', but got '%v'", ext)
+	}
+
+	if mime := OutputFormatAuto.MIMEType(); mime != "audio/mpeg" {
+		t.Errorf("expected MIME type to be 'audio/mpeg', but got '%v'", mime)
+	}
+}
@@ -0,0 +1,395 @@
+package tts
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"slices"
+	"sync"
+	"time"
+)
+
+// DefaultIdleTimeout is time after which unused idle session is closed
+const DefaultIdleTimeout = 30 * time.Second
+
+// ErrPoolClosed is returned by Warmup() after pool is closed
+var ErrPoolClosed = errors.New("connection pool closed")
+
+// errSessionIdle is returned when session became idle while waiting for connection slot
+var errSessionIdle = errors.New("session became idle")
+
+// Pool keeps idle sessions to reuse them in next synthesis turns
+type Pool struct {
+	mu          sync.Mutex
+	idle        []*idleSession
+	maxIdle     int
+	idleTimeout time.Duration
+	options     SessionOptions
+
+	// set by Close(), sessions returned after that are closed instead of kept idle
+	closed bool
+
+	// closed and replaced every time session is added to idle list
+	idleAdded chan struct{}
+
+	// take and give back slot of open connection, nil if count of connections is not limited
+	acquire func(ctx context.Context) error
+	release func()
+}
+
+// idleSession is session waiting in pool with timer to close it after idle timeout
+type idleSession struct {
+	session *Session
+	timer   *time.Timer
+}
+
+// NewPool creates pool which keeps up to maxIdle idle sessions. With maxIdle = 0 every turn uses new connection
+// except sessions opened with Warmup()
+func NewPool(maxIdle int) *Pool {
+	return &Pool{
+		maxIdle:     maxIdle,
+		idleTimeout: DefaultIdleTimeout,
+		idleAdded:   make(chan struct{}),
+	}
+}
+
+// SetMaxIdle sets maximum count of idle sessions
+func (p *Pool) SetMaxIdle(maxIdle int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.maxIdle = maxIdle
+}
+
+// SetIdleTimeout sets time after which unused idle session is closed, 0 means never close
+func (p *Pool) SetIdleTimeout(timeout time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.idleTimeout = timeout
+}
+
+// SetTimeouts sets time limits of operations of new sessions
+func (p *Pool) SetTimeouts(timeouts Timeouts) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.options.Timeouts = timeouts
+}
+
+// SetStrict makes new sessions fail on unknown messages from server
+func (p *Pool) SetStrict(strict bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.options.Strict = strict
+}
+
+// SetOnMessage sets function called for every message of new sessions
+func (p *Pool) SetOnMessage(onMessage func(Message)) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.options.OnMessage = onMessage
+}
+
+// SetOnUnknown sets function called for every unknown message of new sessions
+func (p *Pool) SetOnUnknown(onUnknown func(path string, data []byte)) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.options.OnUnknown = onUnknown
+}
+
+// SetRecorder sets recorder of all messages of new sessions
+func (p *Pool) SetRecorder(recorder *Recorder) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.options.Recorder = recorder
+}
+
+// SetEndpoint sets URL of websocket server used instead of Edge TTS server
+func (p *Pool) SetEndpoint(endpoint string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.options.Endpoint = endpoint
+}
+
+// SetConnectionLimit limits count of open sessions, both in use and idle. Slot is taken with acquire
+// before new session is dialed and given back with release when session is closed
+func (p *Pool) SetConnectionLimit(acquire func(ctx context.Context) error, release func()) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.acquire = acquire
+	p.release = release
+}
+
+// Warmup opens session and sends speech.config ahead of time, so next turn only sends SSML.
+// Warm session is kept in pool even if pool doesn't keep idle sessions.
+func (p *Pool) Warmup(ctx context.Context, format OutputFormat) error {
+	p.mu.Lock()
+	closed := p.closed
+	p.mu.Unlock()
+
+	if closed {
+		return ErrPoolClosed
+	}
+
+	session, err := p.dial(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := session.Configure(format); err != nil {
+		session.Close()
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		session.Close()
+		return ErrPoolClosed
+	}
+
+	if len(p.idle) >= max(p.maxIdle, 1) {
+		session.Close()
+		return nil
+	}
+
+	p.addIdle(session)
+
+	return nil
+}
+
+// Synthesize runs synthesis turn on idle session or on new one. If idle session turns out to be closed by server
+// before any response received, it is transparently replaced by new session.
+func (p *Pool) Synthesize(ctx context.Context, text string, args Args, format OutputFormat) iter.Seq2[ResponseChunk, error] {
+	return func(yield func(ResponseChunk, error) bool) {
+		// verify args before connection to server
+		if _, err := getSpeechParams(text, args, format); err != nil {
+			yield(ResponseChunk{}, err)
+			return
+		}
+
+		for {
+			session, reused, err := p.get(ctx)
+			if err != nil {
+				yield(ResponseChunk{}, err)
+				return
+			}
+
+			if !p.turn(ctx, session, reused, text, args, format, yield) {
+				return
+			}
+		}
+	}
+}
+
+// Close closes all idle sessions. Sessions of turns still running are closed when turns finish
+func (p *Pool) Close() error {
+	p.mu.Lock()
+	idle := p.idle
+	p.idle = nil
+	p.closed = true
+	p.mu.Unlock()
+
+	for _, item := range idle {
+		if item.timer != nil {
+			item.timer.Stop()
+		}
+
+		item.session.Close()
+	}
+
+	return nil
+}
+
+// turn runs synthesis on session and returns true if it should be retried on new session
+func (p *Pool) turn(
+	ctx context.Context,
+	session *Session,
+	reused bool,
+	text string,
+	args Args,
+	format OutputFormat,
+	yield func(ResponseChunk, error) bool,
+) bool {
+	// close connection on context cancellation to interrupt blocked read
+	stop := context.AfterFunc(ctx, func() {
+		session.conn.Close()
+	})
+
+	defer func() {
+		if !stop() {
+			session.reusable = false
+		}
+
+		p.put(session)
+	}()
+
+	received := false
+
+	for chunk, err := range session.Synthesize(text, args, format) {
+		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				err = ctxErr
+			} else if reused && !received {
+				// idle connection was closed by server
+				return true
+			}
+
+			yield(ResponseChunk{}, err)
+			return false
+		}
+
+		received = true
+
+		if !yield(chunk, nil) {
+			return false
+		}
+	}
+
+	return false
+}
+
+// get returns idle session or dials new one. Returns true if session was idle
+func (p *Pool) get(ctx context.Context) (*Session, bool, error) {
+	for {
+		p.mu.Lock()
+		if n := len(p.idle); n > 0 {
+			item := p.idle[n-1]
+			p.idle = p.idle[:n-1]
+			p.mu.Unlock()
+
+			if item.timer != nil {
+				item.timer.Stop()
+			}
+
+			return item.session, true, nil
+		}
+		idleAdded := p.idleAdded
+		p.mu.Unlock()
+
+		session, err := p.dial(ctx, idleAdded)
+		if err == errSessionIdle {
+			// reuse session instead of waiting for connection slot
+			continue
+		}
+
+		if err != nil {
+			return nil, false, err
+		}
+
+		return session, false, nil
+	}
+}
+
+// dial takes connection slot and opens new session. Returns errSessionIdle if idleAdded is closed
+// while waiting for connection slot
+func (p *Pool) dial(ctx context.Context, idleAdded <-chan struct{}) (*Session, error) {
+	p.mu.Lock()
+	acquire, release, options := p.acquire, p.release, p.options
+	p.mu.Unlock()
+
+	if acquire != nil {
+		if err := acquireSlot(ctx, acquire, idleAdded); err != nil {
+			return nil, err
+		}
+	}
+
+	session, err := Dial(ctx, options)
+	if err != nil {
+		if release != nil {
+			release()
+		}
+
+		return nil, err
+	}
+
+	session.release = release
+
+	return session, nil
+}
+
+// acquireSlot waits for connection slot. Returns errSessionIdle if idleAdded is closed while waiting
+func acquireSlot(ctx context.Context, acquire func(ctx context.Context) error, idleAdded <-chan struct{}) error {
+	waitCtx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	if idleAdded != nil {
+		go func() {
+			select {
+			case <-idleAdded:
+				cancel(errSessionIdle)
+			case <-waitCtx.Done():
+			}
+		}()
+	}
+
+	err := acquire(waitCtx)
+	if err != nil && ctx.Err() == nil && context.Cause(waitCtx) == errSessionIdle {
+		return errSessionIdle
+	}
+
+	return err
+}
+
+// put returns session to pool or closes it if it can't be reused, pool is full or closed
+func (p *Pool) put(session *Session) {
+	if session.Reusable() {
+		p.mu.Lock()
+		if !p.closed && len(p.idle) < p.maxIdle {
+			p.addIdle(session)
+			p.mu.Unlock()
+
+			return
+		}
+		p.mu.Unlock()
+	}
+
+	session.Close()
+}
+
+// addIdle adds session to idle list and starts idle timer, session is closed if pool is closed.
+// Should be called with p.mu locked
+func (p *Pool) addIdle(session *Session) {
+	if p.closed {
+		session.Close()
+		return
+	}
+
+	item := &idleSession{
+		session: session,
+	}
+
+	if p.idleTimeout > 0 {
+		item.timer = time.AfterFunc(p.idleTimeout, func() {
+			p.expire(item)
+		})
+	}
+
+	p.idle = append(p.idle, item)
+
+	// wake up turns waiting for connection slot
+	close(p.idleAdded)
+	p.idleAdded = make(chan struct{})
+}
+
+// expire closes idle session after idle timeout if it is still in pool
+func (p *Pool) expire(item *idleSession) {
+	p.mu.Lock()
+	i := slices.Index(p.idle, item)
+	if i < 0 {
+		p.mu.Unlock()
+		return
+	}
+	p.idle = slices.Delete(p.idle, i, i+1)
+	p.mu.Unlock()
+
+	item.session.Close()
+}
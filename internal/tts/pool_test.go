@@ -0,0 +1,479 @@
+package tts
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// turnServerStats contains what scripted turn server received
+type turnServerStats struct {
+	mu          sync.Mutex
+	connections int
+	closed      int
+	formats     [][]string
+	turns       int
+}
+
+// get returns count of accepted connections, output formats of speech.config sent to each of them
+// and count of answered turns
+func (s *turnServerStats) get() (int, [][]string, int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	formats := make([][]string, len(s.formats))
+	for i := range s.formats {
+		formats[i] = slices.Clone(s.formats[i])
+	}
+
+	return s.connections, formats, s.turns
+}
+
+// waitClosed waits until n connections are closed by client
+func (s *turnServerStats) waitClosed(t *testing.T, n int) {
+	t.Helper()
+
+	for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); time.Sleep(time.Millisecond) {
+		s.mu.Lock()
+		closed := s.closed
+		s.mu.Unlock()
+
+		if closed == n {
+			return
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t.Errorf("expected %d connections to be closed, but got %d", n, s.closed)
+}
+
+// scriptedTurnServer starts server which sends messages to client in response to every SSML request.
+// If drop returns true for n-th turn of connection, connection is closed instead of response.
+// Returns websocket URL of server and what server received
+func scriptedTurnServer(t *testing.T, drop func(conn int, turn int) bool, messages ...testMessage) (string, *turnServerStats) {
+	stats := &turnServerStats{}
+
+	upgrader := websocket.Upgrader{
+		CheckOrigin: func(r *http.Request) bool { return true },
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		stats.mu.Lock()
+		index := stats.connections
+		stats.connections++
+		stats.formats = append(stats.formats, nil)
+		stats.mu.Unlock()
+
+		defer func() {
+			stats.mu.Lock()
+			stats.closed++
+			stats.mu.Unlock()
+		}()
+
+		turn := 0
+
+		for {
+			_, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+
+			message, err := ParseMessage(false, data)
+			if err != nil {
+				return
+			}
+
+			switch message.Path {
+			case "speech.config":
+				_, format, _ := strings.Cut(string(message.Body), `"outputFormat":"`)
+				format, _, _ = strings.Cut(format, `"`)
+
+				stats.mu.Lock()
+				stats.formats[index] = append(stats.formats[index], format)
+				stats.mu.Unlock()
+			case "ssml":
+				if drop != nil && drop(index, turn) {
+					return
+				}
+				turn++
+
+				stats.mu.Lock()
+				stats.turns++
+				stats.mu.Unlock()
+
+				for _, message := range messages {
+					conn.WriteMessage(message.messageType, []byte(message.data))
+				}
+			}
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return "ws" + strings.TrimPrefix(server.URL, "http"), stats
+}
+
+var turnMessages = []testMessage{
+	{websocket.TextMessage, "Path:turn.start\r\n\r\n{}"},
+	{websocket.BinaryMessage, "\x00\x0cPath:audio\r\naudio"},
+	{websocket.TextMessage, "Path:turn.end\r\n\r\n{}"},
+}
+
+func Test_PoolSynthesize(t *testing.T) {
+	args := Args{Voice: "en-US-AvaNeural"}
+
+	tests := []struct {
+		name    string
+		maxIdle int
+		formats []OutputFormat
+		drop    func(conn int, turn int) bool
+
+		connections int
+		configs     [][]string
+	}{
+		{
+			name:        "new connection for every turn",
+			maxIdle:     0,
+			formats:     []OutputFormat{OutputFormatMp3, OutputFormatMp3},
+			connections: 2,
+			configs:     [][]string{{OutputFormatMp3.String()}, {OutputFormatMp3.String()}},
+		},
+		{
+			name:        "reuse connection",
+			maxIdle:     1,
+			formats:     []OutputFormat{OutputFormatMp3, OutputFormatMp3, OutputFormatMp3},
+			connections: 1,
+			configs:     [][]string{{OutputFormatMp3.String()}},
+		},
+		{
+			name:        "format changed",
+			maxIdle:     1,
+			formats:     []OutputFormat{OutputFormatMp3, OutputFormatRaw24000, OutputFormatRaw24000},
+			connections: 1,
+			configs:     [][]string{{OutputFormatMp3.String(), OutputFormatRaw24000.String()}},
+		},
+		{
+			name:    "redial when reused connection fails",
+			maxIdle: 1,
+			formats: []OutputFormat{OutputFormatMp3, OutputFormatMp3},
+			drop: func(conn int, turn int) bool {
+				return conn == 0 && turn == 1
+			},
+			connections: 2,
+			configs:     [][]string{{OutputFormatMp3.String()}, {OutputFormatMp3.String()}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			url, stats := scriptedTurnServer(t, tt.drop, turnMessages...)
+
+			pool := NewPool(tt.maxIdle)
+			pool.SetEndpoint(url)
+			defer pool.Close()
+
+			for _, format := range tt.formats {
+				chunks := collect(t, pool.Synthesize(t.Context(), "Hello", args, format))
+
+				if len(chunks) != 2 || string(chunks[0].Data) != "audio" || chunks[1].ChunkType != ChunkTypeEnd {
+					t.Fatalf("unexpected response '%+v'", chunks)
+				}
+			}
+
+			connections, configs, turns := stats.get()
+
+			if connections != tt.connections {
+				t.Errorf("expected %d connections, but got %d", tt.connections, connections)
+			}
+
+			if !slices.EqualFunc(configs, tt.configs, slices.Equal) {
+				t.Errorf("expected speech configs to be '%v', but got '%v'", tt.configs, configs)
+			}
+
+			if turns != len(tt.formats) {
+				t.Errorf("expected %d turns, but got %d", len(tt.formats), turns)
+			}
+		})
+	}
+}
+
+func Test_PoolFailedTurnNotRetried(t *testing.T) {
+	// server sends only part of response, so turn fails after data was received
+	url, stats := scriptedTurnServer(t, nil, turnMessages[:2]...)
+
+	pool := NewPool(1)
+	pool.SetEndpoint(url)
+	pool.SetTimeouts(Timeouts{Idle: 100 * time.Millisecond})
+	defer pool.Close()
+
+	ctx, cancel := context.WithTimeout(t.Context(), time.Second)
+	defer cancel()
+
+	var err error
+	for _, chunkErr := range pool.Synthesize(ctx, "Hello", Args{Voice: "en-US-AvaNeural"}, OutputFormatMp3) {
+		if chunkErr != nil {
+			err = chunkErr
+		}
+	}
+
+	if err != ErrIdleTimeout {
+		t.Errorf("expected error to be '%v', but got '%v'", ErrIdleTimeout, err)
+	}
+
+	if connections, _, _ := stats.get(); connections != 1 {
+		t.Errorf("expected failed turn not to be retried, but got %d connections", connections)
+	}
+}
+
+func Test_PoolWarmup(t *testing.T) {
+	tests := []struct {
+		name        string
+		maxIdle     int
+		idleTimeout time.Duration
+		warmups     int
+		wait        time.Duration
+
+		idle        int
+		closed      int
+		connections int
+	}{
+		{
+			name:        "warm connection used",
+			maxIdle:     0,
+			idleTimeout: DefaultIdleTimeout,
+			warmups:     1,
+			idle:        1,
+			connections: 1,
+		},
+		{
+			name:        "one warm connection without pool",
+			maxIdle:     0,
+			idleTimeout: DefaultIdleTimeout,
+			warmups:     2,
+			idle:        1,
+			closed:      1,
+			connections: 2,
+		},
+		{
+			name:        "warm connections limited by max idle",
+			maxIdle:     2,
+			idleTimeout: DefaultIdleTimeout,
+			warmups:     3,
+			idle:        2,
+			closed:      1,
+			connections: 3,
+		},
+		{
+			name:        "idle timeout closes warm connection",
+			maxIdle:     1,
+			idleTimeout: 50 * time.Millisecond,
+			warmups:     1,
+			wait:        200 * time.Millisecond,
+			idle:        0,
+			closed:      1,
+			connections: 2,
+		},
+		{
+			name:        "zero idle timeout keeps warm connection",
+			maxIdle:     1,
+			idleTimeout: 0,
+			warmups:     1,
+			wait:        200 * time.Millisecond,
+			idle:        1,
+			connections: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			url, stats := scriptedTurnServer(t, nil, turnMessages...)
+
+			pool := NewPool(0)
+			pool.SetMaxIdle(tt.maxIdle)
+			pool.SetIdleTimeout(tt.idleTimeout)
+			pool.SetEndpoint(url)
+			defer pool.Close()
+
+			for range tt.warmups {
+				if err := pool.Warmup(t.Context(), OutputFormatRaw24000); err != nil {
+					t.Fatalf("expected error to be 'nil', but got '%v'", err)
+				}
+			}
+
+			time.Sleep(tt.wait)
+
+			pool.mu.Lock()
+			idle := len(pool.idle)
+			pool.mu.Unlock()
+
+			if idle != tt.idle {
+				t.Errorf("expected %d idle connections, but got %d", tt.idle, idle)
+			}
+
+			stats.waitClosed(t, tt.closed)
+
+			collect(t, pool.Synthesize(t.Context(), "Hello", Args{Voice: "en-US-AvaNeural"}, OutputFormatRaw24000))
+
+			connections, configs, _ := stats.get()
+			if connections != tt.connections {
+				t.Errorf("expected %d connections, but got %d", tt.connections, connections)
+			}
+
+			// warm connection is already configured for format
+			for i, formats := range configs {
+				if len(formats) != 1 || formats[0] != OutputFormatRaw24000.String() {
+					t.Errorf("expected connection %d to be configured once, but got '%v'", i, formats)
+				}
+			}
+		})
+	}
+}
+
+// channelLimit returns connection limit functions of pool backed by buffered channel
+func channelLimit(slots chan struct{}) (func(ctx context.Context) error, func()) {
+	acquire := func(ctx context.Context) error {
+		select {
+		case slots <- struct{}{}:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	release := func() {
+		<-slots
+	}
+
+	return acquire, release
+}
+
+func Test_PoolConnectionLimit(t *testing.T) {
+	url, _ := scriptedTurnServer(t, nil, turnMessages...)
+
+	slots := make(chan struct{}, 1)
+
+	pool := NewPool(1)
+	pool.SetEndpoint(url)
+	pool.SetConnectionLimit(channelLimit(slots))
+
+	if err := pool.Warmup(t.Context(), OutputFormatRaw24000); err != nil {
+		t.Fatalf("expected error to be 'nil', but got '%v'", err)
+	}
+
+	// warm connection holds the only slot
+	ctx, cancel := context.WithTimeout(t.Context(), 50*time.Millisecond)
+	defer cancel()
+
+	if err := pool.Warmup(ctx, OutputFormatRaw24000); err != context.DeadlineExceeded {
+		t.Errorf("expected error to be '%v', but got '%v'", context.DeadlineExceeded, err)
+	}
+
+	// idle connection is used without new slot
+	collect(t, pool.Synthesize(t.Context(), "Hello", Args{Voice: "en-US-AvaNeural"}, OutputFormatRaw24000))
+
+	if len(slots) != 1 {
+		t.Errorf("expected idle connection to hold slot, but got %d slots taken", len(slots))
+	}
+
+	pool.Close()
+
+	if len(slots) != 0 {
+		t.Errorf("expected closed connection to release slot, but got %d slots taken", len(slots))
+	}
+}
+
+func Test_PoolConnectionLimitWaiter(t *testing.T) {
+	url, stats := scriptedTurnServer(t, nil, turnMessages...)
+
+	pool := NewPool(1)
+	pool.SetEndpoint(url)
+	pool.SetConnectionLimit(channelLimit(make(chan struct{}, 1)))
+	defer pool.Close()
+
+	session, _, err := pool.get(t.Context())
+	if err != nil {
+		t.Fatalf("expected error to be 'nil', but got '%v'", err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		var turnErr error
+		for _, err := range pool.Synthesize(t.Context(), "Hello", Args{Voice: "en-US-AvaNeural"}, OutputFormatRaw24000) {
+			if err != nil {
+				turnErr = err
+			}
+		}
+
+		done <- turnErr
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+
+	// waiting turn picks up session returned to pool instead of waiting for slot
+	pool.put(session)
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("expected error to be 'nil', but got '%v'", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected waiting turn to use idle connection")
+	}
+
+	if connections, _, _ := stats.get(); connections != 1 {
+		t.Errorf("expected 1 connection, but got %d", connections)
+	}
+}
+
+func Test_PoolClose(t *testing.T) {
+	url, stats := scriptedTurnServer(t, nil, turnMessages...)
+
+	slots := make(chan struct{}, 2)
+
+	pool := NewPool(2)
+	pool.SetEndpoint(url)
+	pool.SetIdleTimeout(0)
+	pool.SetConnectionLimit(channelLimit(slots))
+
+	// turn is still running when pool is closed
+	for range pool.Synthesize(t.Context(), "Hello", Args{Voice: "en-US-AvaNeural"}, OutputFormatRaw24000) {
+		pool.Close()
+	}
+
+	pool.mu.Lock()
+	idle := len(pool.idle)
+	pool.mu.Unlock()
+
+	if idle != 0 {
+		t.Errorf("expected no idle connections after close, but got %d", idle)
+	}
+
+	if len(slots) != 0 {
+		t.Errorf("expected connection slots to be released, but got %d slots taken", len(slots))
+	}
+
+	stats.waitClosed(t, 1)
+
+	if err := pool.Warmup(t.Context(), OutputFormatRaw24000); err != ErrPoolClosed {
+		t.Errorf("expected error to be '%v', but got '%v'", ErrPoolClosed, err)
+	}
+
+	if connections, _, _ := stats.get(); connections != 1 {
+		t.Errorf("expected warmup of closed pool not to connect, but got %d connections", connections)
+	}
+}
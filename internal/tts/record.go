@@ -0,0 +1,169 @@
+package tts
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// Frame is websocket message recorded in session
+type Frame struct {
+	// Number of connection in recording starting from 1
+	Conn int
+
+	// Time since the first recorded message
+	Time time.Duration
+
+	// True for messages sent by client, false for messages received from server
+	Outgoing bool
+
+	// True for binary messages, false for text messages
+	Binary bool
+
+	// Raw message
+	Data []byte
+}
+
+// frameJSON is line of recording file. Text messages are stored as is, binary messages are base64 encoded
+type frameJSON struct {
+	Conn     int           `json:"conn"`
+	Time     time.Duration `json:"time"`
+	Outgoing bool          `json:"outgoing,omitempty"`
+	Binary   bool          `json:"binary,omitempty"`
+	Data     string        `json:"data"`
+}
+
+// Recorder writes every message sent and received by sessions to writer as JSON lines. It is safe for concurrent use
+type Recorder struct {
+	mu      sync.Mutex
+	encoder *json.Encoder
+	start   time.Time
+	conns   int
+	err     error
+}
+
+// recordingConn passes messages of connection to recorder
+type recordingConn struct {
+	Conn
+	recorder *Recorder
+	id       int
+}
+
+// NewRecorder creates recorder which writes messages to w
+func NewRecorder(w io.Writer) *Recorder {
+	return &Recorder{
+		encoder: json.NewEncoder(w),
+	}
+}
+
+// Err returns the first error of writing recording
+func (r *Recorder) Err() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.err
+}
+
+// wrap returns connection which records its messages with new connection number
+func (r *Recorder) wrap(conn Conn) Conn {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.conns++
+
+	return &recordingConn{
+		Conn:     conn,
+		recorder: r,
+		id:       r.conns,
+	}
+}
+
+// record writes message of connection
+func (r *Recorder) record(conn int, outgoing bool, messageType int, data []byte) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.err != nil {
+		return
+	}
+
+	now := time.Now()
+	if r.start.IsZero() {
+		r.start = now
+	}
+
+	frame := frameJSON{
+		Conn:     conn,
+		Time:     now.Sub(r.start),
+		Outgoing: outgoing,
+		Binary:   messageType == websocket.BinaryMessage,
+		Data:     string(data),
+	}
+
+	if frame.Binary {
+		frame.Data = base64.StdEncoding.EncodeToString(data)
+	}
+
+	r.err = r.encoder.Encode(frame)
+}
+
+func (c *recordingConn) ReadMessage() (int, []byte, error) {
+	messageType, data, err := c.Conn.ReadMessage()
+	if err == nil {
+		c.recorder.record(c.id, false, messageType, data)
+	}
+
+	return messageType, data, err
+}
+
+func (c *recordingConn) WriteMessage(messageType int, data []byte) error {
+	if err := c.Conn.WriteMessage(messageType, data); err != nil {
+		return err
+	}
+
+	c.recorder.record(c.id, true, messageType, data)
+
+	return nil
+}
+
+// ReadFrames reads recording written by Recorder
+func ReadFrames(r io.Reader) ([]Frame, error) {
+	var frames []Frame
+
+	decoder := json.NewDecoder(r)
+
+	for {
+		var line frameJSON
+		if err := decoder.Decode(&line); err != nil {
+			if errors.Is(err, io.EOF) {
+				return frames, nil
+			}
+
+			return nil, err
+		}
+
+		frame := Frame{
+			Conn:     line.Conn,
+			Time:     line.Time,
+			Outgoing: line.Outgoing,
+			Binary:   line.Binary,
+			Data:     []byte(line.Data),
+		}
+
+		if line.Binary {
+			data, err := base64.StdEncoding.DecodeString(line.Data)
+			if err != nil {
+				return nil, err
+			}
+
+			frame.Data = data
+		}
+
+		frames = append(frames, frame)
+	}
+}
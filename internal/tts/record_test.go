@@ -0,0 +1,103 @@
+package tts
+
+import (
+	"bytes"
+	"context"
+	"iter"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+)
+
+// collect returns response chunks or the first error
+func collect(t *testing.T, response iter.Seq2[ResponseChunk, error]) []ResponseChunk {
+	var chunks []ResponseChunk
+
+	for chunk, err := range response {
+		if err != nil {
+			t.Fatalf("expected error to be 'nil', but got '%v'", err)
+		}
+
+		chunk.Data = bytes.Clone(chunk.Data)
+		chunks = append(chunks, chunk)
+	}
+
+	return chunks
+}
+
+func Test_RecordReplay(t *testing.T) {
+	endpoint := scriptedServer(t,
+		testMessage{websocket.TextMessage, "X-RequestId:abc\r\nPath:turn.start\r\n\r\n{}"},
+		testMessage{websocket.BinaryMessage, "\x00\x0cPath:audio\r\nsound"},
+		testMessage{websocket.TextMessage, "Path:audio.metadata\r\n\r\n" +
+			`{"Metadata":[{"Type":"WordBoundary","Data":{"Offset":1000000,"Duration":2000000,"text":{"Text":"Hello"}}}]}`},
+		testMessage{websocket.TextMessage, "Path:turn.end\r\n\r\n{}"},
+	)
+
+	var recording bytes.Buffer
+
+	session, err := Dial(context.Background(), SessionOptions{
+		Endpoint: endpoint,
+		Recorder: NewRecorder(&recording),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer session.Close()
+
+	args := Args{Voice: "en-US-AvaNeural"}
+	original := collect(t, session.Synthesize("Hello", args, OutputFormatMp3))
+
+	frames, err := ReadFrames(&recording)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	outgoing := 0
+	for _, frame := range frames {
+		if frame.Outgoing {
+			outgoing++
+		}
+	}
+
+	if len(frames) != 6 || outgoing != 2 {
+		t.Fatalf("expected speech.config, SSML and 4 received messages, but got '%+v'", frames)
+	}
+
+	if !strings.Contains(string(frames[1].Data), "Path:ssml") || frames[3].Conn != 1 || !frames[3].Binary {
+		t.Errorf("expected frames to keep messages as is, but got '%+v'", frames)
+	}
+
+	t.Run("conn", func(t *testing.T) {
+		replayed := collect(t, ReadResponse(NewReplayConn(frames), SessionOptions{}))
+
+		if !slices.EqualFunc(original, replayed, equalChunks) {
+			t.Errorf("expected replay to be '%+v', but got '%+v'", original, replayed)
+		}
+	})
+
+	t.Run("server", func(t *testing.T) {
+		server := NewReplayServer(frames, false)
+		defer server.Close()
+
+		replay, err := Dial(context.Background(), SessionOptions{
+			Endpoint: server.URL(),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer replay.Close()
+
+		replayed := collect(t, replay.Synthesize("Hello", args, OutputFormatMp3))
+
+		if !slices.EqualFunc(original, replayed, equalChunks) {
+			t.Errorf("expected replay to be '%+v', but got '%+v'", original, replayed)
+		}
+	})
+}
+
+func equalChunks(a, b ResponseChunk) bool {
+	return a.ChunkType == b.ChunkType && bytes.Equal(a.Data, b.Data) && a.Metadata == b.Metadata && a.Path == b.Path
+}
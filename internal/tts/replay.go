@@ -0,0 +1,166 @@
+package tts
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// ReplayConn is connection which returns received messages of recording one by one. Sent messages are discarded
+type ReplayConn struct {
+	mu     sync.Mutex
+	frames []Frame
+	closed bool
+}
+
+// NewReplayConn creates connection which replays messages received in recording
+func NewReplayConn(frames []Frame) *ReplayConn {
+	return &ReplayConn{
+		frames: slices.DeleteFunc(slices.Clone(frames), func(frame Frame) bool {
+			return frame.Outgoing
+		}),
+	}
+}
+
+// ReadMessage returns next received message of recording. When all messages are read it returns close error
+func (c *ReplayConn) ReadMessage() (int, []byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return 0, nil, net.ErrClosed
+	}
+
+	if len(c.frames) == 0 {
+		return 0, nil, &websocket.CloseError{Code: websocket.CloseNormalClosure}
+	}
+
+	frame := c.frames[0]
+	c.frames = c.frames[1:]
+
+	return frameMessageType(frame), frame.Data, nil
+}
+
+func (c *ReplayConn) WriteMessage(messageType int, data []byte) error {
+	return nil
+}
+
+func (c *ReplayConn) WriteControl(messageType int, data []byte, deadline time.Time) error {
+	return nil
+}
+
+func (c *ReplayConn) SetReadDeadline(t time.Time) error {
+	return nil
+}
+
+func (c *ReplayConn) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.closed = true
+
+	return nil
+}
+
+// ReplayServer is local websocket server which replays recording
+type ReplayServer struct {
+	server *httptest.Server
+}
+
+// URL returns websocket URL of server to connect to
+func (s *ReplayServer) URL() string {
+	return "ws" + strings.TrimPrefix(s.server.URL, "http")
+}
+
+// Close stops server and closes its connections
+func (s *ReplayServer) Close() {
+	s.server.Close()
+}
+
+// NewReplayServer starts websocket server which replays recording: n-th accepted connection replays n-th recorded
+// connection. Before messages which followed client message in recording server waits for any message from client,
+// so client should send the same count of messages as in recording. If realtime is true, received messages
+// are delayed as in recording, otherwise they are sent at once.
+func NewReplayServer(frames []Frame, realtime bool) *ReplayServer {
+	var conns [][]Frame
+	index := map[int]int{}
+
+	for _, frame := range frames {
+		i, ok := index[frame.Conn]
+		if !ok {
+			i = len(conns)
+			index[frame.Conn] = i
+			conns = append(conns, nil)
+		}
+
+		conns[i] = append(conns[i], frame)
+	}
+
+	var mu sync.Mutex
+	upgrader := websocket.Upgrader{
+		// client sends Origin of Edge browser extension
+		CheckOrigin: func(r *http.Request) bool { return true },
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		if len(conns) == 0 {
+			mu.Unlock()
+			http.Error(w, "no more recorded connections", http.StatusServiceUnavailable)
+			return
+		}
+
+		frames := conns[0]
+		conns = conns[1:]
+		mu.Unlock()
+
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		var last time.Duration
+
+		for _, frame := range frames {
+			if frame.Outgoing {
+				if _, _, err := conn.ReadMessage(); err != nil {
+					return
+				}
+			} else {
+				if realtime && frame.Time > last {
+					time.Sleep(frame.Time - last)
+				}
+
+				if err := conn.WriteMessage(frameMessageType(frame), frame.Data); err != nil {
+					return
+				}
+			}
+
+			last = frame.Time
+		}
+
+		// wait till client closes connection
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}))
+
+	return &ReplayServer{server: server}
+}
+
+func frameMessageType(frame Frame) int {
+	if frame.Binary {
+		return websocket.BinaryMessage
+	}
+
+	return websocket.TextMessage
+}
@@ -0,0 +1,150 @@
+package tts
+
+import (
+	"context"
+	"iter"
+)
+
+// Session is websocket connection to Edge TTS server which can run many synthesis turns one after another
+type Session struct {
+	conn Conn
+
+	// output format sent in last speech.config, empty if it was not sent yet
+	format string
+
+	// false if last turn failed or was not read till the end, so connection can't be reused
+	reusable bool
+
+	options SessionOptions
+
+	// gives back connection slot when session is closed, may be nil
+	release func()
+}
+
+// SessionOptions contains settings of session
+type SessionOptions struct {
+	// Time limits of operations with server
+	Timeouts Timeouts
+
+	// Fail on unknown messages instead of passing them as ChunkTypeUnknown
+	Strict bool
+
+	// Function called for every sent and received message, may be nil
+	OnMessage func(Message)
+
+	// Function called for every unknown message with its path and raw body, may be nil. Not called in strict mode
+	OnUnknown func(path string, data []byte)
+
+	// Recorder of all sent and received messages, may be nil
+	Recorder *Recorder
+
+	// URL of websocket server used instead of Edge TTS server, e.g. of replay server. Empty means Edge TTS server
+	Endpoint string
+}
+
+// Dial opens new session
+func Dial(ctx context.Context, options SessionOptions) (*Session, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var conn Conn
+
+	conn, err := openWebsocket(ctx, options.Endpoint, options.Timeouts.Handshake)
+	if err != nil {
+		return nil, err
+	}
+
+	if options.Recorder != nil {
+		conn = options.Recorder.wrap(conn)
+	}
+
+	session := &Session{
+		conn:     conn,
+		reusable: true,
+		options:  options,
+	}
+
+	return session, nil
+}
+
+// Close closes session connection
+func (s *Session) Close() error {
+	s.reusable = false
+	err := s.conn.Close()
+
+	if s.release != nil {
+		s.release()
+		s.release = nil
+	}
+
+	return err
+}
+
+// Reusable reports whether session can be used for next turn
+func (s *Session) Reusable() bool {
+	return s.reusable
+}
+
+// Configure sends speech.config with output format if it differs from format sent before
+func (s *Session) Configure(format OutputFormat) error {
+	wireFormat := format.WireFormat().String()
+	if s.format == wireFormat {
+		return nil
+	}
+
+	if err := sendSpeechConfig(s.conn, wireFormat, s.options.OnMessage); err != nil {
+		s.reusable = false
+		return err
+	}
+
+	s.format = wireFormat
+
+	return nil
+}
+
+// Synthesize sends SSML request with new request ID and reads response till the end of turn
+func (s *Session) Synthesize(text string, args Args, format OutputFormat) iter.Seq2[ResponseChunk, error] {
+	return func(yield func(ResponseChunk, error) bool) {
+		// verify args and get TTS params
+		params, err := getSpeechParams(text, args, format)
+		if err != nil {
+			yield(ResponseChunk{}, err)
+			return
+		}
+
+		if err := s.Configure(format); err != nil {
+			yield(ResponseChunk{}, err)
+			return
+		}
+
+		// connection is reusable only if the whole turn is read
+		s.reusable = false
+
+		requestID := uuidWithoutDashes()
+
+		if err := sendSSML(s.conn, requestID, params, s.options.OnMessage); err != nil {
+			yield(ResponseChunk{}, err)
+			return
+		}
+
+		stop := s.options.Timeouts.keepalive(s.conn)
+		defer stop()
+
+		for chunk, err := range ReadResponse(s.conn, s.options) {
+			if err != nil {
+				yield(ResponseChunk{}, err)
+				return
+			}
+
+			if chunk.ChunkType == ChunkTypeEnd {
+				s.reusable = true
+				chunk.RequestID = requestID
+			}
+
+			if !yield(chunk, nil) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package tts
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// DefaultMaxChunkSize is maximum size of text in bytes sent to Edge TTS server in one request
+const DefaultMaxChunkSize = 4096
+
+// SplitText splits text to chunks not longer than maxSize bytes.
+// It tries to split on line breaks, then on sentence ends, then on spaces and never splits UTF-8 characters.
+func SplitText(text string, maxSize int) []string {
+	if maxSize <= 0 {
+		maxSize = DefaultMaxChunkSize
+	}
+
+	var chunks []string
+
+	for len(text) > maxSize {
+		pos := splitPosition(text, maxSize)
+
+		chunk := strings.TrimSpace(text[:pos])
+		if chunk != "" {
+			chunks = append(chunks, chunk)
+		}
+
+		// space left after split point would hide the next one
+		text = strings.TrimLeftFunc(text[pos:], unicode.IsSpace)
+	}
+
+	if chunk := strings.TrimSpace(text); chunk != "" {
+		chunks = append(chunks, chunk)
+	}
+
+	return chunks
+}
+
+// splitPosition finds best position to split text not further than maxSize bytes. Text should be longer than maxSize
+func splitPosition(text string, maxSize int) int {
+	head := text[:maxSize]
+
+	if pos := strings.LastIndex(head, "\n"); pos > 0 {
+		return pos + 1
+	}
+
+	if pos := lastSentenceEnd(head); pos > 0 {
+		return pos
+	}
+
+	if pos := strings.LastIndexFunc(head, unicode.IsSpace); pos > 0 {
+		return pos + 1
+	}
+
+	// no good place to split, so split on UTF-8 character boundary
+	pos := maxSize
+	for pos > 0 && !utf8.RuneStart(text[pos]) {
+		pos--
+	}
+
+	if pos == 0 {
+		return maxSize
+	}
+
+	return pos
+}
+
+// lastSentenceEnd returns position after last sentence end punctuation. Latin punctuation should be
+// followed by space to distinguish it from decimal point or abbreviation, full-width one ends sentence by itself
+func lastSentenceEnd(text string) int {
+	for i := len(text); i > 0; {
+		r, size := utf8.DecodeLastRuneInString(text[:i])
+
+		if strings.ContainsRune("。！？", r) {
+			return i
+		}
+
+		if unicode.IsSpace(r) && strings.ContainsRune(".!?;", lastRune(text[:i-size])) {
+			return i
+		}
+
+		i -= size
+	}
+
+	return 0
+}
+
+func lastRune(text string) rune {
+	r, _ := utf8.DecodeLastRuneInString(text)
+	return r
+}
+
+// SegmentEnd returns position right after last complete sentence or line in text, or 0 if there is no one.
+// If text is longer than maxSize bytes, position of best split not further than maxSize is returned.
+func SegmentEnd(text string, maxSize int) int {
+	if maxSize > 0 && len(text) > maxSize {
+		return splitPosition(text, maxSize)
+	}
+
+	if pos := strings.LastIndex(text, "\n"); pos >= 0 {
+		return pos + 1
+	}
+
+	return lastSentenceEnd(text)
+}
@@ -0,0 +1,120 @@
+package tts
+
+import (
+	"slices"
+	"testing"
+)
+
+func Test_SplitText(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		maxSize int
+		want    []string
+	}{
+		{
+			name:    "short text",
+			text:    "Hello world.",
+			maxSize: 100,
+			want:    []string{"Hello world."},
+		},
+		{
+			name:    "split on line break",
+			text:    "First line\nSecond line",
+			maxSize: 15,
+			want:    []string{"First line", "Second line"},
+		},
+		{
+			name:    "split on sentence end",
+			text:    "One two. Three four five.",
+			maxSize: 16,
+			want:    []string{"One two.", "Three four five."},
+		},
+		{
+			name:    "split on full-width sentence end",
+			text:    "你好世界。今天天气很好！",
+			maxSize: 24,
+			want:    []string{"你好世界。", "今天天气很好！"},
+		},
+		{
+			name:    "split on space",
+			text:    "one two three",
+			maxSize: 9,
+			want:    []string{"one two", "three"},
+		},
+		{
+			name:    "words of max size",
+			text:    "aaaa bbbb cccc",
+			maxSize: 4,
+			want:    []string{"aaaa", "bbbb", "cccc"},
+		},
+		{
+			name:    "split on utf-8 boundary",
+			text:    "приветмир",
+			maxSize: 5,
+			want:    []string{"пр", "ив", "ет", "ми", "р"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SplitText(tt.text, tt.maxSize); !slices.Equal(got, tt.want) {
+				t.Errorf("expected chunks to be '%q', but got '%q'", tt.want, got)
+			}
+		})
+	}
+}
+
+func Test_SegmentEnd(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		maxSize int
+		want    int
+	}{
+		{
+			name:    "incomplete sentence",
+			text:    "Hello wor",
+			maxSize: 100,
+			want:    0,
+		},
+		{
+			name:    "sentence end without space",
+			text:    "Pi is 3.",
+			maxSize: 100,
+			want:    0,
+		},
+		{
+			name:    "complete sentence",
+			text:    "Hello world! How",
+			maxSize: 100,
+			want:    13,
+		},
+		{
+			name:    "full-width sentence end",
+			text:    "你好。世界",
+			maxSize: 100,
+			want:    9,
+		},
+		{
+			name:    "line break",
+			text:    "Title\nText",
+			maxSize: 100,
+			want:    6,
+		},
+		{
+			name:    "too long text",
+			text:    "one two three four",
+			maxSize: 10,
+			want:    8,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SegmentEnd(tt.text, tt.maxSize); got != tt.want {
+				t.Errorf("expected segment end to be '%v', but got '%v'", tt.want, got)
+			}
+		})
+	}
+}
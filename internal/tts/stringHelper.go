@@ -3,6 +3,7 @@ package tts
 import (
 	"bytes"
 	"encoding/hex"
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
@@ -19,10 +20,10 @@ func uuidWithoutDashes() string {
 	return hex.EncodeToString(id[:])
 }
 
-func mkssml(text string, voice string, rate string, volume string) string {
+func mkssml(text string, voice string, rate string, volume string, pitch string) string {
 	return "<speak version='1.0' xmlns='http://www.w3.org/2001/10/synthesis' xml:lang='en-US'>" +
 		"<voice name='" + voice + "'>" +
-		"<prosody pitch='+0Hz' rate='" + rate + "' volume='" + volume + "'>" + text + "</prosody>" +
+		"<prosody pitch='" + pitch + "' rate='" + rate + "' volume='" + volume + "'>" + text + "</prosody>" +
 		"</voice></speak>"
 }
 
@@ -38,10 +39,15 @@ func getCurrentTime() string {
 	return time.Now().UTC().Format("Mon Jan 02 2006 15:04:05 GMT-0700 (Coordinated Universal Time)")
 }
 
-func getPathAndData(data []byte) (string, []byte) {
+func getPathAndData(data []byte) (string, []byte, error) {
 	var path string
 
-	lines := bytes.SplitSeq(data[:bytes.Index(data, doubleEol)], eol)
+	headers, body, ok := bytes.Cut(data, doubleEol)
+	if !ok {
+		return "", nil, fmt.Errorf("message lacks headers separator: %q", data)
+	}
+
+	lines := bytes.SplitSeq(headers, eol)
 
 	for line := range lines {
 		if bytes.Index(line, pathHeader) == 0 {
@@ -50,5 +56,5 @@ func getPathAndData(data []byte) (string, []byte) {
 		}
 	}
 
-	return path, data[bytes.Index(data, doubleEol)+4:]
+	return path, body, nil
 }
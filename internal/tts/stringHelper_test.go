@@ -30,12 +30,34 @@ func Test_getPathAndData(t *testing.T) {
 			data:    []byte(`{"context":{"synthesis":{"audio":{"metadataoptions":{"sentenceBoundaryEnabled":false,"wordBoundaryEnabled":true},"outputFormat":"audio-24khz-48kbitrate-mono-mp3"}}}}`),
 			wantErr: false,
 		},
+		{
+			name: "empty body",
+			args: args{
+				data: []byte("X-RequestId:123\r\nPath:turn.end\r\n\r\n"),
+			},
+			path:    "turn.end",
+			data:    []byte{},
+			wantErr: false,
+		},
+		{
+			name: "no separator",
+			args: args{
+				data: []byte("Path:turn.end"),
+			},
+			path:    "",
+			data:    nil,
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			path, data := getPathAndData(tt.args.data)
+			path, data, err := getPathAndData(tt.args.data)
 			t.Logf("Path: %v\nData: %s\n", path, data)
 
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error to be '%v' but got '%v'", tt.wantErr, err)
+			}
+
 			if path != tt.path {
 				t.Errorf("expected path to be '%s' but got '%s'", tt.path, path)
 			}
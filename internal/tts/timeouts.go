@@ -0,0 +1,94 @@
+package tts
+
+import (
+	"errors"
+	"net"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+var (
+	// ErrHandshakeTimeout is returned when connection to Edge TTS server is not established in time
+	ErrHandshakeTimeout = errors.New("websocket handshake timed out")
+
+	// ErrFirstByteTimeout is returned when server sends no sound data in time after request
+	ErrFirstByteTimeout = errors.New("timed out waiting for first sound data")
+
+	// ErrIdleTimeout is returned when server sends nothing in time between messages of response
+	ErrIdleTimeout = errors.New("timed out waiting for next message")
+)
+
+// Timeouts contains time limits of operations with Edge TTS server. Zero value means no limit
+type Timeouts struct {
+	// Time to open connection: DNS, TCP, TLS and websocket handshake
+	Handshake time.Duration
+
+	// Time from sending request till the first sound data
+	FirstByte time.Duration
+
+	// Time between text or binary messages of response after the first sound data, pongs of server are not counted.
+	// Pings are sent at half of this interval to detect dead connection
+	Idle time.Duration
+}
+
+// readDeadline sets read deadline for next message of turn started at start
+func (t Timeouts) readDeadline(conn Conn, start time.Time, audioReceived bool) error {
+	var deadline time.Time
+
+	switch {
+	case !audioReceived && t.FirstByte > 0:
+		deadline = start.Add(t.FirstByte)
+	case t.Idle > 0:
+		deadline = time.Now().Add(t.Idle)
+	}
+
+	return conn.SetReadDeadline(deadline)
+}
+
+// timeoutError replaces read deadline error with distinct timeout error
+func (t Timeouts) timeoutError(err error, audioReceived bool) error {
+	var netErr net.Error
+	if !errors.As(err, &netErr) || !netErr.Timeout() {
+		return err
+	}
+
+	if !audioReceived && t.FirstByte > 0 {
+		return ErrFirstByteTimeout
+	}
+
+	return ErrIdleTimeout
+}
+
+// keepalive sends pings at half of idle timeout until returned function is called. Connection is closed
+// if ping can't be sent, so blocked read fails at once. Pongs don't move read deadline: server which answers
+// pings but sends no messages still fails with ErrIdleTimeout
+func (t Timeouts) keepalive(conn Conn) func() {
+	if t.Idle <= 0 {
+		return func() {}
+	}
+
+	done := make(chan struct{})
+	ticker := time.NewTicker(t.Idle / 2)
+
+	go func() {
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				// WriteControl is safe to call concurrently with other writes
+				if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(t.Idle/2)); err != nil {
+					conn.Close()
+					return
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+	}
+}
@@ -0,0 +1,132 @@
+package tts
+
+import (
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func Test_ReadResponseTimeouts(t *testing.T) {
+	timeouts := Timeouts{
+		FirstByte: 50 * time.Millisecond,
+		Idle:      50 * time.Millisecond,
+	}
+
+	tests := []struct {
+		name      string
+		sendAudio bool
+		wantErr   error
+	}{
+		{"first byte", false, ErrFirstByteTimeout},
+		{"idle", true, ErrIdleTimeout},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			messages := []testMessage{
+				{websocket.TextMessage, "Path:turn.start\r\n\r\n{}"},
+			}
+
+			if tt.sendAudio {
+				messages = append(messages, testMessage{websocket.BinaryMessage, "\x00\x00audio"})
+			}
+
+			conn := scriptedConn(t, messages...)
+
+			var err error
+			for _, err = range ReadResponse(conn, SessionOptions{Timeouts: timeouts}) {
+				if err != nil {
+					break
+				}
+			}
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("expected error to be '%v', but got '%v'", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func Test_ReadResponsePongsNotCounted(t *testing.T) {
+	timeouts := Timeouts{Idle: 100 * time.Millisecond}
+
+	tests := []struct {
+		name     string
+		messages []testMessage
+	}{
+		{
+			name:     "stall after turn start",
+			messages: []testMessage{{websocket.TextMessage, "Path:turn.start\r\n\r\n{}"}},
+		},
+		{
+			name: "stall in the middle of turn",
+			messages: []testMessage{
+				{websocket.TextMessage, "Path:turn.start\r\n\r\n{}"},
+				{websocket.BinaryMessage, "\x00\x00audio"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// server answers pings but sends no more messages
+			conn := scriptedConn(t, tt.messages...)
+
+			stop := timeouts.keepalive(conn)
+			defer stop()
+
+			start := time.Now()
+
+			var err error
+			for _, err = range ReadResponse(conn, SessionOptions{Timeouts: timeouts}) {
+				if err != nil {
+					break
+				}
+			}
+
+			if !errors.Is(err, ErrIdleTimeout) {
+				t.Errorf("expected error to be '%v', but got '%v'", ErrIdleTimeout, err)
+			}
+
+			if elapsed := time.Since(start); elapsed > 3*timeouts.Idle {
+				t.Errorf("expected idle timeout after %v, but got it after %v", timeouts.Idle, elapsed)
+			}
+		})
+	}
+}
+
+func Test_DialHandshakeTimeout(t *testing.T) {
+	// server accepts TCP connections but never answers websocket handshake
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			t.Cleanup(func() { conn.Close() })
+		}
+	}()
+
+	options := SessionOptions{
+		Timeouts: Timeouts{Handshake: 50 * time.Millisecond},
+		Endpoint: "ws://" + listener.Addr().String(),
+	}
+
+	start := time.Now()
+
+	if _, err := Dial(t.Context(), options); err != ErrHandshakeTimeout {
+		t.Errorf("expected error to be '%v', but got '%v'", ErrHandshakeTimeout, err)
+	}
+
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expected dial to fail after handshake timeout, but it took %v", elapsed)
+	}
+}
@@ -2,11 +2,13 @@ package tts
 
 import (
 	"context"
-	"encoding/binary"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"iter"
+	"net"
 	"net/http"
+	"time"
 
 	"github.com/gorilla/websocket"
 
@@ -18,24 +20,41 @@ type speechParams struct {
 	voice  string
 	rate   string
 	volume string
+	pitch  string
 	format string
+
+	// text is SSML document
+	isSSML bool
 }
 
-type responseChunkType int8
+type ResponseChunkType int8
 
 const (
-	ChunkTypeAudio responseChunkType = iota
+	ChunkTypeAudio ResponseChunkType = iota
 	ChunkTypeWordBoundary
 	ChunkTypeSessionEnd
 	ChunkTypeEnd
+
+	// message with unknown path or metadata with unknown type, passed as is in tolerant mode
+	ChunkTypeUnknown
 )
 
-type responseChunk struct {
-	ChunkType responseChunkType
+// ResponseChunk is piece of Edge TTS server response: audio data or word boundary metadata
+type ResponseChunk struct {
+	ChunkType ResponseChunkType
 	Data      []byte
 	Metadata  SpeechMetadata
+
+	// path of message, set for ChunkTypeUnknown
+	Path string
+
+	// X-RequestId of synthesis turn, set for ChunkTypeEnd
+	RequestID string
 }
 
+// MetadataTick is unit of word timings sent by Edge TTS server
+const MetadataTick = 100 * time.Nanosecond
+
 type SpeechMetadata struct {
 	// Start time of word in generated sound in milliseconds
 	Offset int `json:"offset"`
@@ -45,6 +64,28 @@ type SpeechMetadata struct {
 
 	// Separate word
 	Text string `json:"text"`
+
+	// Start time of word in generated sound with precision of server ticks, Start / MetadataTick is count of ticks
+	Start time.Duration `json:"start"`
+
+	// Duration of word pronunciation with precision of server ticks
+	Length time.Duration `json:"length"`
+}
+
+// Shift returns metadata moved by offset. Millisecond fields are calculated from precise ones,
+// so rounding errors don't accumulate
+func (m SpeechMetadata) Shift(offset time.Duration) SpeechMetadata {
+	// metadata stored before precise fields were added
+	if m.Start == 0 && m.Length == 0 {
+		m.Start = time.Duration(m.Offset) * time.Millisecond
+		m.Length = time.Duration(m.Duration) * time.Millisecond
+	}
+
+	m.Start += offset
+	m.Offset = int(m.Start.Milliseconds())
+	m.Duration = int(m.Length.Milliseconds())
+
+	return m
 }
 
 type audioInfoText struct {
@@ -54,8 +95,8 @@ type audioInfoText struct {
 }
 
 type audioInfo struct {
-	Offset   int           `json:"Offset"`
-	Duration int           `json:"Duration"`
+	Offset   int64         `json:"Offset"`
+	Duration int64         `json:"Duration"`
 	Text     audioInfoText `json:"text"`
 }
 
@@ -65,50 +106,67 @@ type audioMetadata struct {
 }
 
 type audioMetadataJSON struct {
-	Metadata []audioMetadata `json:"Metadata"`
+	Metadata []json.RawMessage `json:"Metadata"`
 }
 
-// SendRequest sends request to Edge TTS server and returns connection to read result
-func SendRequest(ctx context.Context, text string, args Args, format OutputFormat) (*websocket.Conn, error) {
-	if err := ctx.Err(); err != nil {
-		return nil, err
-	}
+// ReadResponse read response from Edge TTS server. Reading fails with ErrFirstByteTimeout or ErrIdleTimeout
+// if server doesn't send messages within timeouts. Unknown messages are passed as ChunkTypeUnknown
+// and to options.OnUnknown if it is set, or fail reading in strict mode. Every received message is passed
+// to options.OnMessage if it is set
+func ReadResponse(conn Conn, options SessionOptions) iter.Seq2[ResponseChunk, error] {
+	return func(yield func(ResponseChunk, error) bool) {
+		timeouts := options.Timeouts
 
-	// verify args and get TTS params
-	speechParams, err := getSpeechParams(text, args, format)
-	if err != nil {
-		return nil, err
-	}
-	// send request to Edge server
-	conn, err := openWebsocket(ctx)
-	if err != nil {
-		return nil, err
-	}
+		// indicate that we are downloading audio data
+		downloadAudio := false
+		audioReceived := false
+		start := time.Now()
+
+		// unknown passes unknown message or fails in strict mode and returns false if reading should stop
+		unknown := func(path string, data []byte, err error) bool {
+			if options.Strict {
+				yield(ResponseChunk{}, err)
+				return false
+			}
 
-	if err := sendWebsocketRequest(conn, speechParams); err != nil {
-		return nil, err
-	}
+			if options.OnUnknown != nil {
+				options.OnUnknown(path, data)
+			}
 
-	return conn, nil
-}
+			chunk := ResponseChunk{
+				ChunkType: ChunkTypeUnknown,
+				Data:      data,
+				Path:      path,
+			}
 
-// ReadResponse read response from Edge TTS server
-func ReadResponse(conn *websocket.Conn) iter.Seq2[responseChunk, error] {
-	return func(yield func(responseChunk, error) bool) {
-		// indicate that we are downloading audio data
-		downloadAudio := false
+			return yield(chunk, nil)
+		}
 
 		for {
+			if err := timeouts.readDeadline(conn, start, audioReceived); err != nil {
+				yield(ResponseChunk{}, err)
+				return
+			}
+
 			// read message
 			messageType, data, err := conn.ReadMessage()
 			if err != nil {
-				yield(responseChunk{}, err)
+				yield(ResponseChunk{}, timeouts.timeoutError(err, audioReceived))
 				return
 			}
 
+			notifyMessage(options.OnMessage, false, messageType, data)
+
 			switch messageType {
 			case websocket.TextMessage:
-				path, data := getPathAndData(data)
+				path, body, err := getPathAndData(data)
+				if err != nil {
+					if !unknown("", data, err) {
+						return
+					}
+
+					continue
+				}
 
 				switch path {
 				// start to receive audio binary
@@ -117,7 +175,7 @@ func ReadResponse(conn *websocket.Conn) iter.Seq2[responseChunk, error] {
 
 				// end to receive data
 				case "turn.end":
-					chunk := responseChunk{
+					chunk := ResponseChunk{
 						ChunkType: ChunkTypeEnd,
 					}
 					if !yield(chunk, nil) {
@@ -129,20 +187,28 @@ func ReadResponse(conn *websocket.Conn) iter.Seq2[responseChunk, error] {
 				// receive metadata
 				case "audio.metadata":
 					audioMetadataJSON := audioMetadataJSON{}
-					if err := json.Unmarshal(data, &audioMetadataJSON); err != nil {
-						yield(responseChunk{}, err)
+					if err := json.Unmarshal(body, &audioMetadataJSON); err != nil {
+						yield(ResponseChunk{}, err)
 						return
 					}
 
-					for _, metadata := range audioMetadataJSON.Metadata {
+					for _, raw := range audioMetadataJSON.Metadata {
+						metadata := audioMetadata{}
+						if err := json.Unmarshal(raw, &metadata); err != nil {
+							yield(ResponseChunk{}, err)
+							return
+						}
+
 						switch metadata.Type {
 						case "WordBoundary":
-							chunk := responseChunk{
+							chunk := ResponseChunk{
 								ChunkType: ChunkTypeWordBoundary,
 								Metadata: SpeechMetadata{
 									Offset:   metadataDurationToMilliseconds(metadata.Data.Offset),
 									Duration: metadataDurationToMilliseconds(metadata.Data.Duration),
 									Text:     metadata.Data.Text.Text,
+									Start:    time.Duration(metadata.Data.Offset) * MetadataTick,
+									Length:   time.Duration(metadata.Data.Duration) * MetadataTick,
 								},
 							}
 							if !yield(chunk, nil) {
@@ -152,39 +218,36 @@ func ReadResponse(conn *websocket.Conn) iter.Seq2[responseChunk, error] {
 							continue
 						default:
 							err = fmt.Errorf("unknown metadata type: %s", metadata.Type)
-							yield(responseChunk{}, err)
-							return
+							if !unknown(path, raw, err) {
+								return
+							}
 						}
 					}
 				case "response":
 				default:
 					err = fmt.Errorf("response from Edge TTS server not recognized: %s", data)
-					yield(responseChunk{}, err)
-					return
+					if !unknown(path, body, err) {
+						return
+					}
 				}
 			case websocket.BinaryMessage:
 				if !downloadAudio {
 					err = fmt.Errorf("unexpected binary message received")
-					yield(responseChunk{}, err)
+					yield(ResponseChunk{}, err)
 					return
 				}
 
-				if len(data) < 2 {
-					err = fmt.Errorf("binary message lacks header length")
-					yield(responseChunk{}, err)
+				message, err := ParseMessage(true, data)
+				if err != nil {
+					yield(ResponseChunk{}, err)
 					return
 				}
 
-				headerLength := int(binary.BigEndian.Uint16(data[:2]))
-				if len(data) < headerLength+2 {
-					err = fmt.Errorf("binary message lacks audio data")
-					yield(responseChunk{}, err)
-					return
-				}
+				audioReceived = true
 
-				chunk := responseChunk{
+				chunk := ResponseChunk{
 					ChunkType: ChunkTypeAudio,
-					Data:      data[headerLength+2:],
+					Data:      message.Body,
 				}
 				if !yield(chunk, nil) {
 					return
@@ -194,11 +257,29 @@ func ReadResponse(conn *websocket.Conn) iter.Seq2[responseChunk, error] {
 	}
 }
 
+func (params speechParams) ssml() string {
+	if params.isSSML {
+		return params.text
+	}
+
+	return mkssml(params.text, params.voice, params.rate, params.volume, params.pitch)
+}
+
 func getSpeechParams(text string, args Args, format OutputFormat) (speechParams, error) {
 	if text == "" {
 		return speechParams{}, fmt.Errorf("text not specified")
 	}
 
+	if args.SSML {
+		params := speechParams{
+			text:   text,
+			format: format.WireFormat().String(),
+			isSSML: true,
+		}
+
+		return params, nil
+	}
+
 	voice, err := args.getVoice()
 	if err != nil {
 		return speechParams{}, err
@@ -214,69 +295,95 @@ func getSpeechParams(text string, args Args, format OutputFormat) (speechParams,
 		return speechParams{}, err
 	}
 
+	pitch, err := args.getPitch()
+	if err != nil {
+		return speechParams{}, err
+	}
+
 	params := speechParams{
 		text:   text,
 		voice:  voice,
 		rate:   rate,
 		volume: volume,
-		format: format.String(),
+		pitch:  pitch,
+		format: format.WireFormat().String(),
 	}
 
 	return params, nil
 }
 
-func openWebsocket(ctx context.Context) (*websocket.Conn, error) {
+func openWebsocket(ctx context.Context, endpoint string, timeout time.Duration) (*websocket.Conn, error) {
 	headers := http.Header{}
 
 	communication.SetHeaders(&headers, wssHeaders)
 
+	dialCtx := ctx
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		dialCtx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	if endpoint == "" {
+		endpoint = communication.GenerateSecURL(wssURL) + "&ConnectionId=" + uuidWithoutDashes()
+	}
+
 	dialer := websocket.Dialer{}
-	conn, _, err := dialer.DialContext(
-		ctx,
-		communication.GenerateSecURL(wssURL)+"&ConnectionId="+uuidWithoutDashes(),
-		headers,
-	)
+	conn, resp, err := dialer.DialContext(dialCtx, endpoint, headers)
 	if err != nil {
-		return nil, err
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+
+		// connection deadline set from dialCtx may expire slightly before dialCtx itself
+		var netErr net.Error
+		if dialCtx.Err() != nil || timeout > 0 && errors.As(err, &netErr) && netErr.Timeout() {
+			return nil, ErrHandshakeTimeout
+		}
+
+		handshakeErr := &HandshakeError{
+			Err: err,
+		}
+
+		if resp != nil {
+			handshakeErr.StatusCode = resp.StatusCode
+			resp.Body.Close()
+		}
+
+		return nil, handshakeErr
 	}
 
 	return conn, nil
 }
 
-func sendWebsocketRequest(conn *websocket.Conn, params speechParams) error {
-	date := getCurrentTime()
-
-	err := conn.WriteMessage(
-		websocket.TextMessage,
+func sendSpeechConfig(conn Conn, format string, onMessage func(Message)) error {
+	return writeMessage(
+		conn,
 		[]byte(
-			"X-Timestamp:"+date+"\r\n"+
+			"X-Timestamp:"+getCurrentTime()+"\r\n"+
 				"Content-Type:application/json; charset=utf-8\r\n"+
 				"Path:speech.config\r\n\r\n"+
 				`{"context":{"synthesis":{"audio":{"metadataoptions":{"sentenceBoundaryEnabled":false,"wordBoundaryEnabled":true},`+
-				`"outputFormat":"`+params.format+`"}}}}`+"\r\n",
+				`"outputFormat":"`+format+`"}}}}`+"\r\n",
 		),
+		onMessage,
 	)
-	if err != nil {
-		return err
-	}
+}
 
-	err = conn.WriteMessage(
-		websocket.TextMessage,
+func sendSSML(conn Conn, requestID string, params speechParams, onMessage func(Message)) error {
+	return writeMessage(
+		conn,
 		[]byte(
 			ssmlHeadersPlusData(
-				uuidWithoutDashes(),
-				date,
-				mkssml(params.text, params.voice, params.rate, params.volume),
+				requestID,
+				getCurrentTime(),
+				params.ssml(),
 			),
 		),
+		onMessage,
 	)
-	if err != nil {
-		return err
-	}
-
-	return nil
 }
 
-func metadataDurationToMilliseconds(time int) int {
-	return time / 10_000
+func metadataDurationToMilliseconds(time int64) int {
+	return int(time / 10_000)
 }
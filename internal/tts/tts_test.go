@@ -0,0 +1,181 @@
+package tts
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+type testMessage struct {
+	messageType int
+	data        string
+}
+
+// scriptedServer starts server which sends messages to client on every connection and waits until client closes it.
+// Returns websocket URL of server
+func scriptedServer(t *testing.T, messages ...testMessage) string {
+	upgrader := websocket.Upgrader{
+		CheckOrigin: func(r *http.Request) bool { return true },
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		for _, message := range messages {
+			conn.WriteMessage(message.messageType, []byte(message.data))
+		}
+
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return "ws" + strings.TrimPrefix(server.URL, "http")
+}
+
+// scriptedConn connects to scripted server
+func scriptedConn(t *testing.T, messages ...testMessage) *websocket.Conn {
+	conn, _, err := websocket.DefaultDialer.Dial(scriptedServer(t, messages...), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return conn
+}
+
+func Test_ReadResponseUnknownMessages(t *testing.T) {
+	messages := []testMessage{
+		{websocket.TextMessage, "Path:turn.start\r\n\r\n{}"},
+		{websocket.TextMessage, "Path:audio.future\r\n\r\n{\"new\":true}"},
+		{websocket.TextMessage, "Path:without.separator"},
+		{websocket.TextMessage, "Path:audio.metadata\r\n\r\n" +
+			`{"Metadata":[{"Type":"SentenceBoundary","Data":{}},` +
+			`{"Type":"WordBoundary","Data":{"Offset":1000000,"Duration":2000000,"text":{"Text":"Hello"}}}]}`},
+		{websocket.BinaryMessage, "\x00\x00audio"},
+		{websocket.TextMessage, "Path:turn.end\r\n\r\n{}"},
+	}
+
+	t.Run("tolerant", func(t *testing.T) {
+		var unknown []string
+		var words []SpeechMetadata
+		var audio []byte
+
+		for chunk, err := range ReadResponse(scriptedConn(t, messages...), SessionOptions{}) {
+			if err != nil {
+				t.Fatalf("expected error to be 'nil', but got '%v'", err)
+			}
+
+			switch chunk.ChunkType {
+			case ChunkTypeUnknown:
+				unknown = append(unknown, chunk.Path+" "+string(chunk.Data))
+			case ChunkTypeWordBoundary:
+				words = append(words, chunk.Metadata)
+			case ChunkTypeAudio:
+				audio = append(audio, chunk.Data...)
+			}
+		}
+
+		expected := []string{
+			`audio.future {"new":true}`,
+			" Path:without.separator",
+			`audio.metadata {"Type":"SentenceBoundary","Data":{}}`,
+		}
+
+		if strings.Join(unknown, "\n") != strings.Join(expected, "\n") {
+			t.Errorf("expected unknown messages to be '%q', but got '%q'", expected, unknown)
+		}
+
+		if len(words) != 1 || words[0] != (SpeechMetadata{Offset: 100, Duration: 200, Text: "Hello", Start: 100 * time.Millisecond, Length: 200 * time.Millisecond}) {
+			t.Errorf("expected one word boundary, but got '%+v'", words)
+		}
+
+		if string(audio) != "audio" {
+			t.Errorf("expected audio to be 'audio', but got '%s'", audio)
+		}
+	})
+
+	t.Run("observer", func(t *testing.T) {
+		var paths, unknown []string
+		options := SessionOptions{
+			OnMessage: func(message Message) {
+				paths = append(paths, message.Path)
+			},
+			OnUnknown: func(path string, data []byte) {
+				unknown = append(unknown, path)
+			},
+		}
+
+		for _, err := range ReadResponse(scriptedConn(t, messages...), options) {
+			if err != nil {
+				t.Fatalf("expected error to be 'nil', but got '%v'", err)
+			}
+		}
+
+		expected := "turn.start audio.future  audio.metadata  turn.end"
+		if got := strings.Join(paths, " "); got != expected {
+			t.Errorf("expected paths to be '%s', but got '%s'", expected, got)
+		}
+
+		if expected := []string{"audio.future", "", "audio.metadata"}; !slices.Equal(unknown, expected) {
+			t.Errorf("expected unknown messages to be '%q', but got '%q'", expected, unknown)
+		}
+	})
+
+	t.Run("strict", func(t *testing.T) {
+		var err error
+		for _, err = range ReadResponse(scriptedConn(t, messages...), SessionOptions{Strict: true}) {
+			if err != nil {
+				break
+			}
+		}
+
+		if err == nil || !strings.Contains(err.Error(), "audio.future") {
+			t.Errorf("expected error about unknown path, but got '%v'", err)
+		}
+	})
+}
+
+func Test_SpeechMetadataShift(t *testing.T) {
+	// 1.2345 ms and 0.9999 ms in server ticks
+	metadata := SpeechMetadata{
+		Offset:   1,
+		Duration: 0,
+		Start:    12_345 * MetadataTick,
+		Length:   9_999 * MetadataTick,
+	}
+
+	// shifting many times by fractional milliseconds doesn't accumulate rounding errors
+	for range 1000 {
+		metadata = metadata.Shift(500 * time.Microsecond)
+	}
+
+	expected := SpeechMetadata{
+		Offset:   501,
+		Duration: 0,
+		Start:    501_234_500 * time.Nanosecond,
+		Length:   999_900 * time.Nanosecond,
+	}
+
+	if metadata != expected {
+		t.Errorf("expected '%+v', but got '%+v'", expected, metadata)
+	}
+
+	// metadata without precise fields
+	legacy := SpeechMetadata{Offset: 100, Duration: 200}.Shift(time.Second)
+	if legacy.Offset != 1100 || legacy.Start != 1100*time.Millisecond || legacy.Length != 200*time.Millisecond {
+		t.Errorf("expected millisecond fields to be used, but got '%+v'", legacy)
+	}
+}
@@ -0,0 +1,73 @@
+package tts
+
+import (
+	"encoding/binary"
+	"io"
+	"math"
+)
+
+const (
+	// WavHeaderSize is size of canonical RIFF header of PCM WAV file
+	WavHeaderSize = 44
+
+	// wavStreamingDataSize is data size placeholder used when final size is not known yet
+	wavStreamingDataSize = math.MaxUint32 - (WavHeaderSize - 8)
+
+	wavFormatPCM = 1
+)
+
+// WavHeader makes RIFF header for PCM sound data of defined size.
+// Use negative dataSize to get streaming-friendly header with maximum possible size.
+func WavHeader(format OutputFormat, dataSize int64) []byte {
+	info := format.Info()
+
+	blockAlign := info.Channels * info.BitDepth / 8
+	byteRate := info.SampleRate * blockAlign
+
+	header := make([]byte, WavHeaderSize)
+
+	copy(header[0:4], "RIFF")
+	copy(header[8:12], "WAVE")
+
+	copy(header[12:16], "fmt ")
+	binary.LittleEndian.PutUint32(header[16:20], 16)
+	binary.LittleEndian.PutUint16(header[20:22], wavFormatPCM)
+	binary.LittleEndian.PutUint16(header[22:24], uint16(info.Channels))
+	binary.LittleEndian.PutUint32(header[24:28], uint32(info.SampleRate))
+	binary.LittleEndian.PutUint32(header[28:32], uint32(byteRate))
+	binary.LittleEndian.PutUint16(header[32:34], uint16(blockAlign))
+	binary.LittleEndian.PutUint16(header[34:36], uint16(info.BitDepth))
+
+	copy(header[36:40], "data")
+
+	SetWavSizes(header, dataSize)
+
+	return header
+}
+
+// SetWavSizes writes RIFF chunk and data chunk sizes to WAV header
+func SetWavSizes(header []byte, dataSize int64) {
+	size := uint32(wavStreamingDataSize)
+	if dataSize >= 0 && dataSize < wavStreamingDataSize {
+		size = uint32(dataSize)
+	}
+
+	binary.LittleEndian.PutUint32(header[4:8], size+WavHeaderSize-8)
+	binary.LittleEndian.PutUint32(header[40:44], size)
+}
+
+// PatchWavSizes writes final RIFF chunk and data chunk sizes to WAV header at the beginning of file
+func PatchWavSizes(w io.WriterAt, dataSize int64) error {
+	header := make([]byte, WavHeaderSize)
+	SetWavSizes(header, dataSize)
+
+	if _, err := w.WriteAt(header[4:8], 4); err != nil {
+		return err
+	}
+
+	if _, err := w.WriteAt(header[40:44], 40); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,65 @@
+package tts
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func Test_WavHeader(t *testing.T) {
+	header := WavHeader(OutputFormatWav22050, 1000)
+
+	if !bytes.Equal(header[0:4], []byte("RIFF")) || !bytes.Equal(header[8:16], []byte("WAVEfmt ")) {
+		t.Fatalf("expected RIFF header, but got '%q'", header)
+	}
+
+	fields := []struct {
+		name string
+		got  uint32
+		want uint32
+	}{
+		{"riff size", binary.LittleEndian.Uint32(header[4:8]), 1036},
+		{"channels", uint32(binary.LittleEndian.Uint16(header[22:24])), 1},
+		{"sample rate", binary.LittleEndian.Uint32(header[24:28]), 22_050},
+		{"byte rate", binary.LittleEndian.Uint32(header[28:32]), 44_100},
+		{"bit depth", uint32(binary.LittleEndian.Uint16(header[34:36])), 16},
+		{"data size", binary.LittleEndian.Uint32(header[40:44]), 1000},
+	}
+
+	for _, f := range fields {
+		if f.got != f.want {
+			t.Errorf("expected %s to be '%v', but got '%v'", f.name, f.want, f.got)
+		}
+	}
+
+	streaming := WavHeader(OutputFormatWav22050, -1)
+	if size := binary.LittleEndian.Uint32(streaming[4:8]); size != 0xFFFFFFFF {
+		t.Errorf("expected streaming riff size to be max uint32, but got '%v'", size)
+	}
+}
+
+func Test_PatchWavSizes(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "test.wav")
+
+	data := append(WavHeader(OutputFormatWav24000, -1), make([]byte, 480)...)
+	if err := os.WriteFile(filename, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := os.OpenFile(filename, os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := PatchWavSizes(file, 480); err != nil {
+		t.Fatalf("expected error to be 'nil', but got '%v'", err)
+	}
+	file.Close()
+
+	patched, _ := os.ReadFile(filename)
+	if !bytes.Equal(patched[:WavHeaderSize], WavHeader(OutputFormatWav24000, 480)) {
+		t.Errorf("expected header sizes to be patched, but got '%q'", patched[:WavHeaderSize])
+	}
+}
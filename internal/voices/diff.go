@@ -0,0 +1,157 @@
+package voices
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// VoiceChange describes voice which exists in both catalogs but has different fields
+type VoiceChange struct {
+	// Voice from old catalog
+	Old Voice `json:"Old"`
+
+	// Voice from new catalog
+	New Voice `json:"New"`
+
+	// Names of changed fields, e.g. "Status" or "VoiceTag.VoicePersonalities"
+	Fields []string `json:"Fields"`
+}
+
+// StatusChanged reports whether voice status has changed, e.g. from Preview to GA
+func (c VoiceChange) StatusChanged() bool {
+	return slices.Contains(c.Fields, "Status")
+}
+
+// CatalogDiff contains differences between two voice catalogs. Voices are matched by ShortName
+type CatalogDiff struct {
+	// Voices which exist only in new catalog
+	Added []Voice `json:"Added"`
+
+	// Voices which exist only in old catalog
+	Removed []Voice `json:"Removed"`
+
+	// Voices which exist in both catalogs but differ
+	Changed []VoiceChange `json:"Changed"`
+}
+
+// Empty reports whether catalogs are equal
+func (d CatalogDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// DiffVoices compares two voice catalogs and returns added, removed and changed voices.
+// Result slices are sorted by ShortName.
+func DiffVoices(oldVoices []Voice, newVoices []Voice) CatalogDiff {
+	oldByName := indexVoices(oldVoices)
+	newByName := indexVoices(newVoices)
+
+	diff := CatalogDiff{}
+
+	for name, newVoice := range newByName {
+		oldVoice, ok := oldByName[name]
+		if !ok {
+			diff.Added = append(diff.Added, newVoice)
+			continue
+		}
+
+		if fields := changedFields(oldVoice, newVoice); len(fields) > 0 {
+			diff.Changed = append(diff.Changed, VoiceChange{
+				Old:    oldVoice,
+				New:    newVoice,
+				Fields: fields,
+			})
+		}
+	}
+
+	for name, oldVoice := range oldByName {
+		if _, ok := newByName[name]; !ok {
+			diff.Removed = append(diff.Removed, oldVoice)
+		}
+	}
+
+	byShortName := func(a, b Voice) int {
+		return strings.Compare(a.ShortName, b.ShortName)
+	}
+
+	slices.SortFunc(diff.Added, byShortName)
+	slices.SortFunc(diff.Removed, byShortName)
+	slices.SortFunc(diff.Changed, func(a, b VoiceChange) int {
+		return byShortName(a.New, b.New)
+	})
+
+	return diff
+}
+
+// ReadVoices reads voice catalog in the same JSON format as Edge TTS server returns it
+func ReadVoices(r io.Reader) ([]Voice, error) {
+	var voices []Voice
+	if err := json.NewDecoder(r).Decode(&voices); err != nil {
+		return nil, err
+	}
+
+	return voices, nil
+}
+
+// WriteVoices writes voice catalog as JSON so it can be read back with ReadVoices
+func WriteVoices(w io.Writer, voices []Voice) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(voices)
+}
+
+func indexVoices(voices []Voice) map[string]Voice {
+	result := make(map[string]Voice, len(voices))
+	for _, voice := range voices {
+		result[voice.ShortName] = voice
+	}
+
+	return result
+}
+
+func changedFields(a, b Voice) []string {
+	var fields []string
+
+	if a.Name != b.Name {
+		fields = append(fields, "Name")
+	}
+	if a.Gender != b.Gender {
+		fields = append(fields, "Gender")
+	}
+	if a.Locale != b.Locale {
+		fields = append(fields, "Locale")
+	}
+	if a.SuggestedCodec != b.SuggestedCodec {
+		fields = append(fields, "SuggestedCodec")
+	}
+	if a.FriendlyName != b.FriendlyName {
+		fields = append(fields, "FriendlyName")
+	}
+	if a.Status != b.Status {
+		fields = append(fields, "Status")
+	}
+	if !slices.Equal(a.VoiceTag.ContentCategories, b.VoiceTag.ContentCategories) {
+		fields = append(fields, "VoiceTag.ContentCategories")
+	}
+	if !slices.Equal(a.VoiceTag.VoicePersonalities, b.VoiceTag.VoicePersonalities) {
+		fields = append(fields, "VoiceTag.VoicePersonalities")
+	}
+	if !maps.EqualFunc(a.Extra, b.Extra, equalJSON) {
+		fields = append(fields, "Extra")
+	}
+
+	return fields
+}
+
+// equalJSON compares JSON values ignoring insignificant whitespace
+func equalJSON(a, b json.RawMessage) bool {
+	var compactA, compactB bytes.Buffer
+	if json.Compact(&compactA, a) != nil || json.Compact(&compactB, b) != nil {
+		return bytes.Equal(a, b)
+	}
+	return bytes.Equal(compactA.Bytes(), compactB.Bytes())
+}
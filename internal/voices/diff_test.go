@@ -0,0 +1,129 @@
+package voices
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func Test_DiffVoices(t *testing.T) {
+	ava := Voice{ShortName: "en-US-AvaNeural", Gender: "Female", Locale: "en-US", Status: "GA"}
+	emil := Voice{ShortName: "ro-RO-EmilNeural", Gender: "Male", Locale: "ro-RO", Status: "GA"}
+	duarte := Voice{ShortName: "pt-PT-DuarteNeural", Gender: "Male", Locale: "pt-PT", Status: "Preview"}
+
+	duarteGA := duarte
+	duarteGA.Status = "GA"
+
+	avaExtra := ava
+	avaExtra.Extra = map[string]json.RawMessage{"SecondaryLocaleList": json.RawMessage(`["en-GB","fr-FR"]`)}
+
+	avaExtraSpaced := ava
+	avaExtraSpaced.Extra = map[string]json.RawMessage{"SecondaryLocaleList": json.RawMessage(`[ "en-GB", "fr-FR" ]`)}
+
+	avaExtraChanged := ava
+	avaExtraChanged.Extra = map[string]json.RawMessage{"SecondaryLocaleList": json.RawMessage(`["en-GB"]`)}
+
+	tests := []struct {
+		name    string
+		old     []Voice
+		new     []Voice
+		added   []string
+		removed []string
+		changed []string
+		fields  []string
+	}{
+		{
+			name: "equal catalogs",
+			old:  []Voice{ava, emil},
+			new:  []Voice{emil, ava},
+		},
+		{
+			name:    "added and removed",
+			old:     []Voice{ava, emil},
+			new:     []Voice{ava, duarte},
+			added:   []string{"pt-PT-DuarteNeural"},
+			removed: []string{"ro-RO-EmilNeural"},
+		},
+		{
+			name:    "status changed",
+			old:     []Voice{ava, duarte},
+			new:     []Voice{ava, duarteGA},
+			changed: []string{"pt-PT-DuarteNeural"},
+			fields:  []string{"Status"},
+		},
+		{
+			name: "extra formatting differs",
+			old:  []Voice{avaExtra},
+			new:  []Voice{avaExtraSpaced},
+		},
+		{
+			name:    "extra changed",
+			old:     []Voice{avaExtra},
+			new:     []Voice{avaExtraChanged},
+			changed: []string{"en-US-AvaNeural"},
+			fields:  []string{"Extra"},
+		},
+	}
+
+	shortNames := func(voices []Voice) []string {
+		var names []string
+		for _, v := range voices {
+			names = append(names, v.ShortName)
+		}
+		return names
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diff := DiffVoices(tt.old, tt.new)
+
+			if got := shortNames(diff.Added); !slices.Equal(got, tt.added) {
+				t.Errorf("expected added to be '%v', but got '%v'", tt.added, got)
+			}
+
+			if got := shortNames(diff.Removed); !slices.Equal(got, tt.removed) {
+				t.Errorf("expected removed to be '%v', but got '%v'", tt.removed, got)
+			}
+
+			var changed []string
+			var fields []string
+			for _, c := range diff.Changed {
+				changed = append(changed, c.New.ShortName)
+				fields = append(fields, c.Fields...)
+			}
+
+			if !slices.Equal(changed, tt.changed) {
+				t.Errorf("expected changed to be '%v', but got '%v'", tt.changed, changed)
+			}
+
+			if !slices.Equal(fields, tt.fields) {
+				t.Errorf("expected changed fields to be '%v', but got '%v'", tt.fields, fields)
+			}
+
+			if diff.Empty() != (len(tt.added)+len(tt.removed)+len(tt.changed) == 0) {
+				t.Errorf("unexpected Empty() result '%v'", diff.Empty())
+			}
+		})
+	}
+}
+
+func Test_ReadWriteVoices(t *testing.T) {
+	voices := []Voice{
+		{ShortName: "en-US-AvaNeural", Gender: "Female", Locale: "en-US", Status: "GA"},
+	}
+
+	var sb strings.Builder
+	if err := WriteVoices(&sb, voices); err != nil {
+		t.Fatalf("expected error to be 'nil', but got '%v'", err)
+	}
+
+	got, err := ReadVoices(strings.NewReader(sb.String()))
+	if err != nil {
+		t.Fatalf("expected error to be 'nil', but got '%v'", err)
+	}
+
+	if diff := DiffVoices(voices, got); !diff.Empty() {
+		t.Errorf("expected round trip to keep voices, but got diff '%+v'", diff)
+	}
+}
@@ -17,7 +17,7 @@ type Voice struct {
 	ShortName string `json:"ShortName"`
 
 	// Speaker gender
-	Gender string `json:"Gender"`
+	Gender Gender `json:"Gender"`
 
 	// Locale
 	Locale string `json:"Locale"`
@@ -29,10 +29,13 @@ type Voice struct {
 	FriendlyName string `json:"FriendlyName"`
 
 	// GA for General Availability or Preview
-	Status string `json:"Status"`
+	Status Status `json:"Status"`
 
 	// Additional information
 	VoiceTag VoiceTag `json:"VoiceTag"`
+
+	// Fields of voice list JSON which are not known to this struct yet
+	Extra map[string]json.RawMessage `json:"-"`
 }
 
 type VoiceTag struct {
@@ -0,0 +1,152 @@
+package voices
+
+import (
+	"bytes"
+	"encoding/json"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// Gender of speaker
+type Gender string
+
+// Speaker gender possible values
+const (
+	GenderUnknown Gender = ""
+	GenderMale    Gender = "Male"
+	GenderFemale  Gender = "Female"
+	GenderNeutral Gender = "Neutral"
+)
+
+func (g Gender) String() string {
+	return string(g)
+}
+
+// Status of voice availability
+type Status string
+
+// Voice status possible values
+const (
+	StatusUnknown    Status = ""
+	StatusGA         Status = "GA"
+	StatusPreview    Status = "Preview"
+	StatusDeprecated Status = "Deprecated"
+)
+
+func (s Status) String() string {
+	return string(s)
+}
+
+// knownFields is list of voice list JSON fields mapped to Voice struct fields
+var knownFields = []string{
+	"Name",
+	"ShortName",
+	"Gender",
+	"Locale",
+	"SuggestedCodec",
+	"FriendlyName",
+	"Status",
+	"VoiceTag",
+}
+
+// isKnownField reports whether key is mapped to Voice struct field.
+// Keys are matched case-insensitively the same way encoding/json does
+func isKnownField(key string) bool {
+	return slices.ContainsFunc(knownFields, func(known string) bool {
+		return strings.EqualFold(key, known)
+	})
+}
+
+// Language returns language part of voice locale, e.g. "zh" for "zh-CN-guangxi"
+func (v Voice) Language() string {
+	language, _ := ParseLocale(v.Locale)
+	return language
+}
+
+// Region returns region part of voice locale, e.g. "CN" for "zh-CN-guangxi"
+func (v Voice) Region() string {
+	_, region := ParseLocale(v.Locale)
+	return region
+}
+
+// Multilingual reports whether voice can speak multiple languages, e.g. "en-US-AvaMultilingualNeural"
+func (v Voice) Multilingual() bool {
+	return strings.Contains(v.ShortName, "Multilingual") || strings.Contains(v.Name, "Multilingual")
+}
+
+// ParseLocale splits locale to language and region, dropping any variant:
+// "en-US" -> ("en", "US"), "zh-CN-guangxi" -> ("zh", "CN")
+func ParseLocale(locale string) (string, string) {
+	language, rest, _ := strings.Cut(locale, "-")
+	region, _, _ := strings.Cut(rest, "-")
+	return language, region
+}
+
+// voiceFields is used to (un)marshal Voice without recursive MarshalJSON() and UnmarshalJSON() calls
+type voiceFields Voice
+
+// UnmarshalJSON decodes voice and keeps unknown fields in Extra
+func (v *Voice) UnmarshalJSON(data []byte) error {
+	var fields voiceFields
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+
+	var all map[string]json.RawMessage
+	if err := json.Unmarshal(data, &all); err != nil {
+		return err
+	}
+
+	maps.DeleteFunc(all, func(key string, _ json.RawMessage) bool {
+		return isKnownField(key)
+	})
+
+	fields.Extra = nil
+	if len(all) > 0 {
+		fields.Extra = all
+	}
+
+	*v = Voice(fields)
+
+	return nil
+}
+
+// MarshalJSON encodes voice together with unknown fields from Extra
+func (v Voice) MarshalJSON() ([]byte, error) {
+	data, err := json.Marshal(voiceFields(v))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(v.Extra) == 0 {
+		return data, nil
+	}
+
+	buf := bytes.NewBuffer(data[:len(data)-1])
+
+	for _, key := range slices.Sorted(maps.Keys(v.Extra)) {
+		if isKnownField(key) {
+			continue
+		}
+
+		name, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+
+		value, err := json.Marshal(v.Extra[key])
+		if err != nil {
+			return nil, err
+		}
+
+		buf.WriteByte(',')
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+
+	buf.WriteByte('}')
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,85 @@
+package voices
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func Test_VoiceJSON(t *testing.T) {
+	data := []byte(`{"Name":"Microsoft Server Speech Text to Speech Voice (zh-CN-guangxi, YunqiNeural)",` +
+		`"ShortName":"zh-CN-guangxi-YunqiNeural","Gender":"Male","Locale":"zh-CN-guangxi",` +
+		`"SuggestedCodec":"audio-24khz-48kbitrate-mono-mp3","FriendlyName":"","Status":"GA",` +
+		`"VoiceTag":{"ContentCategories":["General"],"VoicePersonalities":["Friendly"]},"WordsPerMinute":"150"}`)
+
+	var voice Voice
+	if err := json.Unmarshal(data, &voice); err != nil {
+		t.Fatalf("expected error to be 'nil', but got '%v'", err)
+	}
+
+	if voice.Gender != GenderMale {
+		t.Errorf("expected gender to be '%v', but got '%v'", GenderMale, voice.Gender)
+	}
+
+	if voice.Status != StatusGA {
+		t.Errorf("expected status to be '%v', but got '%v'", StatusGA, voice.Status)
+	}
+
+	if voice.Language() != "zh" || voice.Region() != "CN" {
+		t.Errorf("expected locale to be parsed to ('zh', 'CN'), but got ('%v', '%v')", voice.Language(), voice.Region())
+	}
+
+	if voice.Multilingual() {
+		t.Error("expected voice not to be multilingual")
+	}
+
+	if string(voice.Extra["WordsPerMinute"]) != `"150"` {
+		t.Errorf("expected extra field to be kept, but got '%v'", voice.Extra)
+	}
+
+	encoded, err := json.Marshal(voice)
+	if err != nil {
+		t.Fatalf("expected error to be 'nil', but got '%v'", err)
+	}
+
+	var decoded Voice
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		t.Fatalf("expected error to be 'nil', but got '%v'", err)
+	}
+
+	if diff := DiffVoices([]Voice{voice}, []Voice{decoded}); !diff.Empty() {
+		t.Errorf("expected round trip to keep voice, but got '%s'", encoded)
+	}
+}
+
+func Test_VoiceJSONKnownFieldCase(t *testing.T) {
+	data := []byte(`{"shortName":"en-US-AvaNeural","locale":"en-US"}`)
+
+	var voice Voice
+	if err := json.Unmarshal(data, &voice); err != nil {
+		t.Fatalf("expected error to be 'nil', but got '%v'", err)
+	}
+
+	if voice.ShortName != "en-US-AvaNeural" {
+		t.Errorf("expected short name to be 'en-US-AvaNeural', but got '%v'", voice.ShortName)
+	}
+
+	if len(voice.Extra) != 0 {
+		t.Errorf("expected no extra fields, but got '%v'", voice.Extra)
+	}
+
+	voice.Extra = map[string]json.RawMessage{"shortName": json.RawMessage(`"other"`)}
+
+	encoded, err := json.Marshal(voice)
+	if err != nil {
+		t.Fatalf("expected error to be 'nil', but got '%v'", err)
+	}
+
+	var decoded Voice
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		t.Fatalf("expected error to be 'nil', but got '%v'", err)
+	}
+
+	if decoded.ShortName != "en-US-AvaNeural" {
+		t.Errorf("expected extra field not to override short name, but got '%s'", encoded)
+	}
+}
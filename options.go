@@ -0,0 +1,116 @@
+package edgetts
+
+import (
+	"time"
+
+	"github.com/kolonist/edgetts/internal/flight"
+	"github.com/kolonist/edgetts/internal/tts"
+)
+
+// Option configures EdgeTTS
+type Option func(*EdgeTTS)
+
+// Timeouts contains time limits of operations with Edge TTS server, see WithTimeouts()
+type Timeouts = tts.Timeouts
+
+// Errors returned when Edge TTS server doesn't respond within timeouts
+var (
+	// ErrHandshakeTimeout is returned when connection is not established within Timeouts.Handshake
+	ErrHandshakeTimeout = tts.ErrHandshakeTimeout
+
+	// ErrFirstByteTimeout is returned when no sound data received within Timeouts.FirstByte after request
+	ErrFirstByteTimeout = tts.ErrFirstByteTimeout
+
+	// ErrIdleTimeout is returned when server sends nothing within Timeouts.Idle in the middle of response
+	ErrIdleTimeout = tts.ErrIdleTimeout
+)
+
+// WithConnectionPool keeps websocket connections to Edge TTS server open after synthesis to reuse them.
+// Reused connection skips DNS, TLS and websocket handshake, so short texts are synthesized much faster.
+// Connections closed by server are detected and replaced by new ones transparently.
+//
+// Parameters:
+//
+//	maxIdle - maximum count of idle connections kept open, 0 disables reuse (default)
+//
+// Returns:
+//
+//	option to pass to New()
+func WithConnectionPool(maxIdle int) Option {
+	return func(etts *EdgeTTS) {
+		etts.pool.SetMaxIdle(maxIdle)
+	}
+}
+
+// WithIdleTimeout sets time after which unused idle connection is closed.
+//
+// Parameters:
+//
+//	timeout - idle time, default is 30 seconds, 0 means never close idle connections
+//
+// Returns:
+//
+//	option to pass to New()
+func WithIdleTimeout(timeout time.Duration) Option {
+	return func(etts *EdgeTTS) {
+		etts.pool.SetIdleTimeout(timeout)
+	}
+}
+
+// WithTimeouts limits time of handshake, waiting for the first sound data and waiting between messages of response.
+// Without timeouts stalled server is detected only by cancellation of context passed by caller.
+// Not to be confused with WithIdleTimeout() which closes unused connections.
+//
+// Parameters:
+//
+//	timeouts - time limits, zero field means no limit
+//
+// Returns:
+//
+//	option to pass to New()
+func WithTimeouts(timeouts Timeouts) Option {
+	return func(etts *EdgeTTS) {
+		etts.pool.SetTimeouts(timeouts)
+	}
+}
+
+// WithStrictProtocol makes synthesis fail on messages of Edge TTS server with unknown path or metadata type.
+// By default such messages are skipped, so new server features don't break synthesis. Useful in tests.
+//
+// Returns:
+//
+//	option to pass to New()
+func WithStrictProtocol() Option {
+	return func(etts *EdgeTTS) {
+		etts.pool.SetStrict(true)
+	}
+}
+
+// WithUnknownMessageHandler sets function called for each skipped message with unknown path or metadata type,
+// e.g. to log it. Function may be called from several goroutines at once.
+//
+// Parameters:
+//
+//	fn - function to call with message path and raw body, body of metadata is JSON of one metadata item
+//
+// Returns:
+//
+//	option to pass to New()
+func WithUnknownMessageHandler(fn func(path string, data []byte)) Option {
+	return func(etts *EdgeTTS) {
+		etts.pool.SetOnUnknown(fn)
+	}
+}
+
+// WithDeduplication makes concurrent syntheses with identical parameters share one request to Edge TTS server.
+// Each caller gets its own copy of sound data and metadata. Cancelling one caller doesn't cancel shared request,
+// it is cancelled only when all callers leave.
+//
+// Returns:
+//
+//	option to pass to New()
+func WithDeduplication() Option {
+	return func(etts *EdgeTTS) {
+		etts.flights = &flight.Group{}
+	}
+}
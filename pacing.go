@@ -0,0 +1,136 @@
+package edgetts
+
+import (
+	"context"
+	"time"
+
+	"github.com/kolonist/edgetts/internal/tts"
+)
+
+// pacer releases sound data at playback speed and fires word boundary events at their offsets
+type pacer struct {
+	ctx   context.Context
+	lead  time.Duration
+	timer *tts.AudioTimer
+
+	// time of the first sound data and duration of sound data released so far
+	start  time.Time
+	played time.Duration
+
+	// word boundaries waiting for their offsets
+	words  []SpeechMetadata
+	onWord func(SpeechMetadata)
+}
+
+// WithPacing releases sound data from GetSoundIter() at playback speed instead of as fast as server sends it.
+// Duration of raw formats is counted by exact byte rate, duration of mp3 is counted by frame durations.
+//
+// Parameters:
+//
+//	lead - how much sound data can be released ahead of playback time, e.g. to fill player buffer
+//
+// Returns:
+//
+//	the same speaker to chain calls
+func (s *Speaker) WithPacing(lead time.Duration) *Speaker {
+	s.pacing = true
+	s.lead = lead
+	return s
+}
+
+// OnWordBoundary sets function called for each word in text. With pacing it is called when playback
+// reaches word offset, otherwise it is called as soon as word boundary comes from server.
+//
+// Parameters:
+//
+//	fn - function to call with word metadata
+//
+// Returns:
+//
+//	the same speaker to chain calls
+func (s *Speaker) OnWordBoundary(fn func(SpeechMetadata)) *Speaker {
+	s.onWord = fn
+	return s
+}
+
+func newPacer(ctx context.Context, format OutputFormat, lead time.Duration, onWord func(SpeechMetadata)) *pacer {
+	return &pacer{
+		ctx:    ctx,
+		lead:   lead,
+		timer:  tts.NewAudioTimer(format),
+		onWord: onWord,
+	}
+}
+
+// audio waits for playback time of sound data
+func (p *pacer) audio(data []byte) error {
+	if p.start.IsZero() {
+		p.start = time.Now()
+	}
+
+	if err := p.wait(p.played - p.lead); err != nil {
+		return err
+	}
+
+	p.played += p.timer.Duration(data)
+
+	return nil
+}
+
+// word schedules word boundary event
+func (p *pacer) word(metadata SpeechMetadata) {
+	p.words = append(p.words, metadata)
+}
+
+// finish waits till all word boundary events fired
+func (p *pacer) finish() error {
+	if len(p.words) == 0 {
+		return nil
+	}
+
+	if p.start.IsZero() {
+		p.start = time.Now()
+	}
+
+	last := p.words[len(p.words)-1]
+
+	return p.wait(wordOffset(last))
+}
+
+// wait waits until playback time and fires word boundary events due before it
+func (p *pacer) wait(until time.Duration) error {
+	for {
+		elapsed := time.Since(p.start)
+
+		// fire due word boundary events
+		for len(p.words) > 0 && wordOffset(p.words[0]) <= elapsed {
+			if p.onWord != nil {
+				p.onWord(p.words[0])
+			}
+
+			p.words = p.words[1:]
+		}
+
+		if elapsed >= until {
+			return nil
+		}
+
+		next := until
+		if len(p.words) > 0 {
+			next = min(next, wordOffset(p.words[0]))
+		}
+
+		timer := time.NewTimer(next - elapsed)
+
+		select {
+		case <-timer.C:
+		case <-p.ctx.Done():
+			timer.Stop()
+			return p.ctx.Err()
+		}
+	}
+}
+
+func wordOffset(metadata SpeechMetadata) time.Duration {
+	return metadata.Start
+}
@@ -0,0 +1,104 @@
+package edgetts
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_WithPacing(t *testing.T) {
+	// test server sends text of each turn as its sound: word of 4800 bytes is 100ms of 16 bit 24khz PCM,
+	// chunk size makes every word separate turn with separate sound data
+	word := strings.Repeat("a", OutputFormatRaw24000.BytesPerSecond()/10)
+	text := strings.Join([]string{word, word, word}, " ")
+	chunk := 100 * time.Millisecond
+
+	// allowed delay of sound data and events after their playback time
+	const tolerance = 80 * time.Millisecond
+
+	tests := []struct {
+		name   string
+		pacing bool
+		lead   time.Duration
+
+		// expected time of the last sound data and of word boundary events since the first sound data
+		lastAudio time.Duration
+		words     []time.Duration
+	}{
+		{
+			// word boundary events come as soon as their turn is received
+			name:      "without pacing",
+			lastAudio: 0,
+			words:     []time.Duration{0, 0, 0},
+		},
+		{
+			name:      "playback speed",
+			pacing:    true,
+			lastAudio: 2 * chunk,
+			words:     []time.Duration{0, chunk, 2 * chunk},
+		},
+		{
+			name:      "lead",
+			pacing:    true,
+			lead:      150 * time.Millisecond,
+			lastAudio: 2*chunk - 150*time.Millisecond,
+			words:     []time.Duration{0, chunk, 2 * chunk},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newEdgeServer(t)
+
+			// time of the first sound data, the first word boundary event may fire right before it
+			var start time.Time
+			since := func() time.Duration {
+				if start.IsZero() {
+					start = time.Now()
+				}
+
+				return time.Since(start)
+			}
+
+			var words []time.Duration
+
+			speaker := newTestEdgeTTS(server).Speak(text).WithChunkSize(len(word)).OnWordBoundary(func(SpeechMetadata) {
+				words = append(words, since())
+			})
+
+			if tt.pacing {
+				speaker.WithPacing(tt.lead)
+			}
+
+			var lastAudio time.Duration
+			var size int
+
+			for data, err := range speaker.GetSoundIter(t.Context(), OutputFormatRaw24000) {
+				if err != nil {
+					t.Fatalf("expected error to be 'nil', but got '%v'", err)
+				}
+
+				lastAudio = since()
+				size += len(data)
+			}
+
+			if size != 3*len(word) {
+				t.Fatalf("expected %d bytes of sound, but got %d", 3*len(word), size)
+			}
+
+			if lastAudio < tt.lastAudio || lastAudio > tt.lastAudio+tolerance {
+				t.Errorf("expected the last sound data at %v, but got it at %v", tt.lastAudio, lastAudio)
+			}
+
+			if len(words) != len(tt.words) {
+				t.Fatalf("expected %d word boundary events, but got %d", len(tt.words), len(words))
+			}
+
+			for i, want := range tt.words {
+				if words[i] < want || words[i] > want+tolerance {
+					t.Errorf("expected word %d at %v, but got it at %v", i, want, words[i])
+				}
+			}
+		})
+	}
+}
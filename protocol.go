@@ -0,0 +1,43 @@
+package edgetts
+
+import "github.com/kolonist/edgetts/internal/tts"
+
+// ProtocolMessage is websocket message of Edge TTS protocol with its path, headers and raw body.
+// Text messages have JSON or SSML body, binary messages have audio data body and their own headers.
+type ProtocolMessage = tts.Message
+
+// ProtocolHeader is header of protocol message, e.g. X-RequestId, Content-Type or Path
+type ProtocolHeader = tts.Header
+
+// ParseProtocolMessage parses raw websocket message of Edge TTS protocol.
+//
+// Parameters:
+//
+//	isBinary - true for binary websocket message, false for text one
+//	data - raw message
+//
+// Returns:
+//
+//	parsed message, if message is malformed its Body contains the whole message
+//	error if message is malformed
+func ParseProtocolMessage(isBinary bool, data []byte) (ProtocolMessage, error) {
+	return tts.ParseMessage(isBinary, data)
+}
+
+// WithProtocolObserver sets function called for every message sent to or received from Edge TTS server:
+// speech.config, SSML, turn.start, response, audio.metadata, audio frames and turn.end, including messages
+// which are ignored by synthesis. Messages of one request share X-RequestId header. Function is called
+// synchronously from several goroutines at once, so it should be fast and safe for concurrent use.
+//
+// Parameters:
+//
+//	fn - function to call with every message
+//
+// Returns:
+//
+//	option to pass to New()
+func WithProtocolObserver(fn func(ProtocolMessage)) Option {
+	return func(etts *EdgeTTS) {
+		etts.pool.SetOnMessage(fn)
+	}
+}
@@ -0,0 +1,93 @@
+package edgetts
+
+import (
+	"io"
+
+	"github.com/kolonist/edgetts/internal/tts"
+)
+
+// Recorder writes every message sent to and received from Edge TTS server to file as JSON lines:
+// speech.config, SSML and all text and binary messages of response with time since the first message.
+type Recorder = tts.Recorder
+
+// RecordedFrame is websocket message read from recording
+type RecordedFrame = tts.Frame
+
+// NewRecorder creates recorder to pass to WithRecorder(). Recorder is safe for concurrent use,
+// messages of different connections are distinguished by RecordedFrame.Conn.
+//
+// Parameters:
+//
+//	w - writer of recording, e.g. file
+//
+// Returns:
+//
+//	new recorder, its Err() method returns the first error of writing
+func NewRecorder(w io.Writer) *Recorder {
+	return tts.NewRecorder(w)
+}
+
+// ReadRecording reads recording written by Recorder.
+//
+// Parameters:
+//
+//	r - reader of recording
+//
+// Returns:
+//
+//	recorded messages in order they were sent and received
+//	error if recording is malformed
+func ReadRecording(r io.Reader) ([]RecordedFrame, error) {
+	return tts.ReadFrames(r)
+}
+
+// ReplayServer is local websocket server which replays recording, see NewReplayServer()
+type ReplayServer = tts.ReplayServer
+
+// NewReplayServer starts local websocket server which replays recording to use it with WithEndpoint().
+// N-th connection to server replays n-th recorded connection. Server waits for message from client
+// where client sent message in recording, so synthesis should be made with the same parameters.
+// Close server when it is not needed anymore.
+//
+// Parameters:
+//
+//	frames - recording
+//	realtime - delay messages as in recording, otherwise send them at once
+//
+// Returns:
+//
+//	started server, pass server.URL() to WithEndpoint()
+func NewReplayServer(frames []RecordedFrame, realtime bool) *ReplayServer {
+	return tts.NewReplayServer(frames, realtime)
+}
+
+// WithRecorder records every message of every connection to Edge TTS server.
+//
+// Parameters:
+//
+//	recorder - recorder created with NewRecorder()
+//
+// Returns:
+//
+//	option to pass to New()
+func WithRecorder(recorder *Recorder) Option {
+	return func(etts *EdgeTTS) {
+		etts.pool.SetRecorder(recorder)
+	}
+}
+
+// WithEndpoint connects to websocket server at url instead of Edge TTS server, e.g. to server started
+// with NewReplayServer().
+//
+// Parameters:
+//
+//	url - websocket URL like "ws://127.0.0.1:8080"
+//
+// Returns:
+//
+//	option to pass to New()
+func WithEndpoint(url string) Option {
+	return func(etts *EdgeTTS) {
+		etts.pool.SetEndpoint(url)
+	}
+}
@@ -0,0 +1,110 @@
+package edgetts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+)
+
+// Registry keeps named synthesizers and falls back to the next one when synthesis fails.
+// It implements Synthesizer itself, so it can be used instead of single engine
+type Registry struct {
+	mu           sync.RWMutex
+	names        []string
+	synthesizers map[string]Synthesizer
+}
+
+// NewRegistry creates empty registry
+func NewRegistry() *Registry {
+	return &Registry{
+		synthesizers: make(map[string]Synthesizer),
+	}
+}
+
+// Register adds synthesizer to the end of fallback order or replaces synthesizer registered with the same name.
+//
+// Parameters:
+//
+//	name - name of synthesizer, e.g. "edge" or "espeak"
+//	synthesizer - synthesizer to add
+func (r *Registry) Register(name string, synthesizer Synthesizer) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.synthesizers[name]; !ok {
+		r.names = append(r.names, name)
+	}
+
+	r.synthesizers[name] = synthesizer
+}
+
+// Get returns synthesizer by name.
+//
+// Parameters:
+//
+//	name - name of synthesizer
+//
+// Returns:
+//
+//	synthesizer or nil if there is no synthesizer with this name
+//	true if synthesizer found
+func (r *Registry) Get(name string) (Synthesizer, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	synthesizer, ok := r.synthesizers[name]
+	return synthesizer, ok
+}
+
+// Names returns names of registered synthesizers in fallback order
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return slices.Clone(r.names)
+}
+
+// Synthesize implements Synthesizer: tries registered synthesizers in order of registration
+// until one of them starts generation. Synthesis is not retried if context is done.
+//
+// Parameters:
+//
+//	ctx - context to stop operation before it finished
+//	request - text and parameters of speech
+//
+// Returns:
+//
+//	stream of sound data of the first successful synthesizer. Should be closed after use
+//	errors of all synthesizers if none succeeded
+func (r *Registry) Synthesize(ctx context.Context, request SynthesisRequest) (AudioStream, error) {
+	r.mu.RLock()
+	names := slices.Clone(r.names)
+	synthesizers := make([]Synthesizer, len(names))
+	for i, name := range names {
+		synthesizers[i] = r.synthesizers[name]
+	}
+	r.mu.RUnlock()
+
+	if len(synthesizers) == 0 {
+		return nil, fmt.Errorf("no synthesizers registered")
+	}
+
+	var errs []error
+
+	for i, synthesizer := range synthesizers {
+		stream, err := synthesizer.Synthesize(ctx, request)
+		if err == nil {
+			return stream, nil
+		}
+
+		errs = append(errs, fmt.Errorf("%s: %w", names[i], err))
+
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+	}
+
+	return nil, errors.Join(errs...)
+}
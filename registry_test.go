@@ -0,0 +1,193 @@
+package edgetts
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// stubStream is sound data of stubSynthesizer
+type stubStream struct {
+	io.Reader
+}
+
+func (s stubStream) Close() error {
+	return nil
+}
+
+func (s stubStream) Metadata() []SpeechMetadata {
+	return nil
+}
+
+// stubSynthesizer returns err or stream with data and counts calls
+type stubSynthesizer struct {
+	data  string
+	err   error
+	calls int
+}
+
+func (s *stubSynthesizer) Synthesize(ctx context.Context, request SynthesisRequest) (AudioStream, error) {
+	s.calls++
+
+	if s.err != nil {
+		return nil, s.err
+	}
+
+	return stubStream{strings.NewReader(s.data)}, nil
+}
+
+func Test_RegistrySynthesize(t *testing.T) {
+	errFailed := errors.New("failed")
+
+	tests := []struct {
+		name         string
+		synthesizers []Synthesizer
+		data         string
+		calls        []int
+		wantErrs     []error
+	}{
+		{
+			name:         "first succeeds",
+			synthesizers: []Synthesizer{&stubSynthesizer{data: "first"}, &stubSynthesizer{data: "second"}},
+			data:         "first",
+			calls:        []int{1, 0},
+		},
+		{
+			name:         "fallback on error",
+			synthesizers: []Synthesizer{&stubSynthesizer{err: errFailed}, &stubSynthesizer{data: "second"}},
+			data:         "second",
+			calls:        []int{1, 1},
+		},
+		{
+			name: "fallback on unsupported format",
+			synthesizers: []Synthesizer{
+				NewCommandSynthesizer(OutputFormatRaw24000, "/bin/sh", "-c", "cat"),
+				&stubSynthesizer{data: "second"},
+			},
+			data:  "second",
+			calls: []int{0, 1},
+		},
+		{
+			name:         "all failed",
+			synthesizers: []Synthesizer{&stubSynthesizer{err: errFailed}, &stubSynthesizer{err: ErrUnsupportedFormat}},
+			calls:        []int{1, 1},
+			wantErrs:     []error{errFailed, ErrUnsupportedFormat},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := NewRegistry()
+			for i, synthesizer := range tt.synthesizers {
+				registry.Register(string(rune('a'+i)), synthesizer)
+			}
+
+			stream, err := registry.Synthesize(t.Context(), SynthesisRequest{Text: "Hello", Format: OutputFormatMp3})
+
+			for _, wantErr := range tt.wantErrs {
+				if !errors.Is(err, wantErr) {
+					t.Errorf("expected error to match '%v', but got '%v'", wantErr, err)
+				}
+			}
+
+			if tt.wantErrs == nil {
+				if err != nil {
+					t.Fatalf("expected error to be 'nil', but got '%v'", err)
+				}
+				defer stream.Close()
+
+				if data, _ := io.ReadAll(stream); string(data) != tt.data {
+					t.Errorf("expected sound to be '%s', but got '%s'", tt.data, data)
+				}
+			}
+
+			for i, synthesizer := range tt.synthesizers {
+				if stub, ok := synthesizer.(*stubSynthesizer); ok && stub.calls != tt.calls[i] {
+					t.Errorf("expected synthesizer %d to be called %d times, but got %d", i, tt.calls[i], stub.calls)
+				}
+			}
+		})
+	}
+}
+
+func Test_RegistryOrder(t *testing.T) {
+	first := &stubSynthesizer{err: errors.New("failed")}
+	replaced := &stubSynthesizer{data: "replaced"}
+
+	registry := NewRegistry()
+	registry.Register("edge", &stubSynthesizer{data: "edge"})
+	registry.Register("espeak", &stubSynthesizer{data: "espeak"})
+	registry.Register("edge", first)
+
+	if names := registry.Names(); !slices.Equal(names, []string{"edge", "espeak"}) {
+		t.Errorf("expected replaced synthesizer to keep its place, but got '%v'", names)
+	}
+
+	registry.Register("espeak", replaced)
+
+	stream, err := registry.Synthesize(t.Context(), SynthesisRequest{Text: "Hello"})
+	if err != nil {
+		t.Fatalf("expected error to be 'nil', but got '%v'", err)
+	}
+	defer stream.Close()
+
+	if data, _ := io.ReadAll(stream); string(data) != "replaced" {
+		t.Errorf("expected sound of replaced synthesizer, but got '%s'", data)
+	}
+
+	if first.calls != 1 {
+		t.Errorf("expected the first synthesizer to be tried once, but got %d", first.calls)
+	}
+}
+
+func Test_RegistryContextDone(t *testing.T) {
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+
+	second := &stubSynthesizer{data: "second"}
+
+	registry := NewRegistry()
+	registry.Register("first", &stubSynthesizer{err: context.Canceled})
+	registry.Register("second", second)
+
+	if _, err := registry.Synthesize(ctx, SynthesisRequest{Text: "Hello"}); err != context.Canceled {
+		t.Errorf("expected error to be '%v', but got '%v'", context.Canceled, err)
+	}
+
+	if second.calls != 0 {
+		t.Errorf("expected no fallback after context is done, but got %d calls", second.calls)
+	}
+}
+
+func Test_RegistryFallbackFromEdgeTTS(t *testing.T) {
+	// nothing listens on closed server
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+
+	etts := New(Args{Voice: testVoice}, WithEndpoint("ws"+strings.TrimPrefix(server.URL, "http")))
+
+	for _, format := range []OutputFormat{OutputFormatRaw24000, OutputFormatWav24000} {
+		t.Run(format.String(), func(t *testing.T) {
+			fallback := &stubSynthesizer{data: "fallback"}
+
+			registry := NewRegistry()
+			registry.Register("edge", etts)
+			registry.Register("fallback", fallback)
+
+			stream, err := registry.Synthesize(t.Context(), SynthesisRequest{Text: "Hello", Format: format})
+			if err != nil {
+				t.Fatalf("expected error to be 'nil', but got '%v'", err)
+			}
+			defer stream.Close()
+
+			if data, _ := io.ReadAll(stream); string(data) != "fallback" {
+				t.Errorf("expected sound of fallback synthesizer, but got '%q'", data)
+			}
+		})
+	}
+}
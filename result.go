@@ -0,0 +1,108 @@
+package edgetts
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/kolonist/edgetts/internal/tts"
+)
+
+// Result contains synthesized sound with its metadata and statistics of synthesis
+type Result struct {
+	// Sound data in requested format
+	Audio []byte
+
+	// Timings of each word in text
+	Metadata []SpeechMetadata
+
+	// Format of sound data
+	Format OutputFormat
+
+	// Approximate playback duration of sound, duration of webm and ogg formats is estimated from bitrate
+	Duration time.Duration
+
+	// Time from the start of synthesis till the first sound data from server
+	TimeToFirstByte time.Duration
+
+	// Time of the whole synthesis
+	WallTime time.Duration
+
+	// X-RequestId of each request sent to Edge TTS server, turns served from cache have no request ID
+	RequestIDs []string
+
+	// Voice used for synthesis, empty for SSML document
+	Voice string
+
+	// Size of sound data in bytes
+	Bytes int
+
+	// Count of synthesis turns: long text is split into several turns
+	Turns int
+}
+
+// Synthesize generate speech and return it with metadata and statistics in one call.
+//
+// Parameters:
+//
+//	ctx - context to stop operation before it finished
+//	format - format of sound data. Use one of OutputFormat* constants
+//
+// Returns:
+//
+//	synthesized sound, metadata and statistics
+//	error if generation or data transferring failed
+func (s *Speaker) Synthesize(ctx context.Context, format OutputFormat) (*Result, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+
+	result := &Result{
+		Format: format,
+	}
+
+	if !s.args.SSML {
+		result.Voice = s.args.Voice
+	}
+
+	audio := make([]byte, 0, getBytesCount(s.text, format))
+	timer := tts.NewAudioTimer(format)
+
+	// WAV header is yielded right before the first data from server
+	header := format.IsWav()
+
+	for data, err := range s.GetSoundIter(ctx, format) {
+		if err != nil {
+			return nil, err
+		}
+
+		audio = append(audio, data...)
+
+		if header {
+			header = false
+			continue
+		}
+
+		if result.TimeToFirstByte == 0 {
+			result.TimeToFirstByte = time.Since(start)
+		}
+
+		timer.Duration(data)
+	}
+
+	if format.IsWav() && len(audio) >= tts.WavHeaderSize {
+		tts.SetWavSizes(audio, int64(len(audio)-tts.WavHeaderSize))
+	}
+
+	result.Audio = audio
+	result.Metadata = s.metadata
+	result.Duration = timer.Total()
+	result.WallTime = time.Since(start)
+	result.RequestIDs = slices.Clone(s.requestIDs)
+	result.Bytes = len(audio)
+	result.Turns = s.turns
+
+	return result, nil
+}
@@ -0,0 +1,94 @@
+package edgetts
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kolonist/edgetts/internal/tts"
+)
+
+func Test_Synthesize(t *testing.T) {
+	server := newEdgeServer(t)
+	etts := newTestEdgeTTS(server)
+
+	text := "One two. Three four."
+
+	result, err := etts.Speak(text).WithChunkSize(12).Synthesize(t.Context(), OutputFormatRaw24000)
+	if err != nil {
+		t.Fatalf("expected error to be 'nil', but got '%v'", err)
+	}
+
+	// test server sends text of each turn as its sound
+	_, _, texts := server.stats()
+	sound := strings.Join(texts, "")
+
+	if len(texts) != 2 || string(result.Audio) != sound || result.Bytes != len(sound) {
+		t.Errorf("expected %d bytes of sound '%s', but got %d bytes '%s'", len(sound), sound, result.Bytes, result.Audio)
+	}
+
+	if result.Turns != 2 {
+		t.Errorf("expected 2 turns, but got %d", result.Turns)
+	}
+
+	if len(result.RequestIDs) != 2 || result.RequestIDs[0] == "" || result.RequestIDs[0] == result.RequestIDs[1] {
+		t.Errorf("expected 2 distinct request IDs, but got '%q'", result.RequestIDs)
+	}
+
+	// 16 bit 24khz PCM
+	duration := time.Duration(len(sound)) * time.Second / time.Duration(OutputFormatRaw24000.BytesPerSecond())
+	if result.Duration != duration {
+		t.Errorf("expected duration to be %v, but got %v", duration, result.Duration)
+	}
+
+	if len(result.Metadata) != 4 || result.Voice != testVoice || result.Format != OutputFormatRaw24000 {
+		t.Errorf("unexpected result '%+v'", result)
+	}
+}
+
+func Test_SynthesizeCached(t *testing.T) {
+	server := newEdgeServer(t)
+	etts := newTestEdgeTTS(server, WithCache(NewMemoryCache(CacheOptions{})))
+
+	if _, err := etts.Speak("Hello world").Synthesize(t.Context(), OutputFormatRaw24000); err != nil {
+		t.Fatalf("expected error to be 'nil', but got '%v'", err)
+	}
+
+	result, err := etts.Speak("Hello world").Synthesize(t.Context(), OutputFormatRaw24000)
+	if err != nil {
+		t.Fatalf("expected error to be 'nil', but got '%v'", err)
+	}
+
+	// turns served from cache have no request ID
+	if result.Turns != 1 || len(result.RequestIDs) != 0 {
+		t.Errorf("expected 1 turn without request ID, but got %d turns with '%q'", result.Turns, result.RequestIDs)
+	}
+}
+
+func Test_SynthesizeTimeToFirstByte(t *testing.T) {
+	const delay = 100 * time.Millisecond
+
+	for _, format := range []OutputFormat{OutputFormatRaw24000, OutputFormatWav24000} {
+		t.Run(format.String(), func(t *testing.T) {
+			server := newEdgeServer(t)
+			server.setRespond(func(text string) bool {
+				time.Sleep(delay)
+				return true
+			})
+
+			result, err := newTestEdgeTTS(server).Speak("Hello world").Synthesize(t.Context(), format)
+			if err != nil {
+				t.Fatalf("expected error to be 'nil', but got '%v'", err)
+			}
+
+			// WAV header is generated locally and is not the first byte from server
+			if result.TimeToFirstByte < delay || result.TimeToFirstByte > result.WallTime {
+				t.Errorf("expected time to first byte between %v and %v, but got %v", delay, result.WallTime, result.TimeToFirstByte)
+			}
+
+			if format.IsWav() && result.Bytes != len("Hello world")+tts.WavHeaderSize {
+				t.Errorf("expected sound with WAV header, but got %d bytes", result.Bytes)
+			}
+		})
+	}
+}
@@ -6,43 +6,171 @@ import (
 	"iter"
 	"os"
 	"path/filepath"
+	"runtime"
+	"time"
 
 	"github.com/kolonist/edgetts/internal/tts"
 )
 
 // Speaker used to get synthesized sound
 type Speaker struct {
+	etts     *EdgeTTS
 	text     string
 	args     Args
 	ready    bool
 	metadata []SpeechMetadata
+
+	// count of synthesis turns and request IDs of turns sent to server in last synthesis
+	turns      int
+	requestIDs []string
+
+	// mode of file created by SaveToFile()
+	fileMode os.FileMode
+
+	// count of text chunks synthesized simultaneously and maximum size of text chunk
+	concurrency int
+	chunkSize   int
+
+	// release sound at playback speed with lead buffer and call onWord at word offsets
+	pacing bool
+	lead   time.Duration
+	onWord func(SpeechMetadata)
 }
 
+// defaultFileMode is mode of file created by SaveToFile() if not set with WithFileMode()
+const defaultFileMode os.FileMode = 0644
+
 // SpeechMetadata contains time of word start and its pronunciation duration im milliseconds
+// and with precision of server ticks
 type SpeechMetadata = tts.SpeechMetadata
 
+// MetadataTick is unit of word timings sent by Edge TTS server, SpeechMetadata.Start / MetadataTick is count of ticks
+const MetadataTick = tts.MetadataTick
+
 // OutputFormat represents sound data output format
 type OutputFormat = tts.OutputFormat
 
-// Sound data output formats possible values
+// OutputFormatInfo describes sound data of output format: MIME type, file extension, codec, sample rate etc.
+type OutputFormatInfo = tts.OutputFormatInfo
+
+// Sound data output formats possible values.
+// Any other format supported by Edge TTS server can be passed verbatim as OutputFormat("format-string")
 const (
+	// infer format from file extension in SaveToFile(), mp3 in other methods
+	OutputFormatAuto = tts.OutputFormatAuto
+
 	// mp3 24khz, 48k bitrate (default)
 	OutputFormatMp3 = tts.OutputFormatMp3
 
+	// mp3 16khz, 32k bitrate
+	OutputFormatMp3At16kHz32kbps = tts.OutputFormatMp3At16kHz32kbps
+
+	// mp3 16khz, 64k bitrate
+	OutputFormatMp3At16kHz64kbps = tts.OutputFormatMp3At16kHz64kbps
+
+	// mp3 16khz, 128k bitrate
+	OutputFormatMp3At16kHz128kbps = tts.OutputFormatMp3At16kHz128kbps
+
+	// mp3 24khz, 96k bitrate
+	OutputFormatMp3At24kHz96kbps = tts.OutputFormatMp3At24kHz96kbps
+
+	// mp3 24khz, 160k bitrate
+	OutputFormatMp3At24kHz160kbps = tts.OutputFormatMp3At24kHz160kbps
+
+	// mp3 48khz, 96k bitrate
+	OutputFormatMp3At48kHz96kbps = tts.OutputFormatMp3At48kHz96kbps
+
+	// mp3 48khz, 192k bitrate
+	OutputFormatMp3At48kHz192kbps = tts.OutputFormatMp3At48kHz192kbps
+
 	// webm 24khz, 16bit, 24k bitrate
 	OutputFormatWebm = tts.OutputFormatWebm
 
+	// webm 16khz, 16bit
+	OutputFormatWebm16000 = tts.OutputFormatWebm16000
+
+	// webm 24khz, 16bit
+	OutputFormatWebm24000 = tts.OutputFormatWebm24000
+
 	// ogg 24khz, 16bit
 	OutputFormatOgg = tts.OutputFormatOgg
 
+	// ogg 16khz, 16bit
+	OutputFormatOgg16000 = tts.OutputFormatOgg16000
+
+	// ogg 48khz, 16bit
+	OutputFormatOgg48000 = tts.OutputFormatOgg48000
+
+	// raw PCM 8000 hz, 16bit
+	OutputFormatRaw8000 = tts.OutputFormatRaw8000
+
+	// raw PCM 16000 hz, 16bit
+	OutputFormatRaw16000 = tts.OutputFormatRaw16000
+
 	// raw PCM 22050 hz, 16bit
 	OutputFormatRaw22050 = tts.OutputFormatRaw22050
 
+	// raw PCM 24000 hz, 16bit
+	OutputFormatRaw24000 = tts.OutputFormatRaw24000
+
 	// raw PCM 44100 hz, 16bit
 	OutputFormatRaw44100 = tts.OutputFormatRaw44100
+
+	// raw PCM 48000 hz, 16bit
+	OutputFormatRaw48000 = tts.OutputFormatRaw48000
+
+	// raw mu-law 8000 hz, 8bit
+	OutputFormatMulaw8000 = tts.OutputFormatMulaw8000
+
+	// raw a-law 8000 hz, 8bit
+	OutputFormatAlaw8000 = tts.OutputFormatAlaw8000
+
+	// WAV PCM 8000 hz, 16bit
+	OutputFormatWav8000 = tts.OutputFormatWav8000
+
+	// WAV PCM 16000 hz, 16bit
+	OutputFormatWav16000 = tts.OutputFormatWav16000
+
+	// WAV PCM 22050 hz, 16bit
+	OutputFormatWav22050 = tts.OutputFormatWav22050
+
+	// WAV PCM 24000 hz, 16bit
+	OutputFormatWav24000 = tts.OutputFormatWav24000
+
+	// WAV PCM 44100 hz, 16bit
+	OutputFormatWav44100 = tts.OutputFormatWav44100
+
+	// WAV PCM 48000 hz, 16bit
+	OutputFormatWav48000 = tts.OutputFormatWav48000
 )
 
+// OutputFormats returns all output formats known to be supported by Edge TTS server.
+//
+// Returns:
+//
+//	slice of OutputFormat* constants
+func OutputFormats() []OutputFormat {
+	return tts.OutputFormats()
+}
+
+// ParseOutputFormat gets output format from its name or file extension.
+//
+// Parameters:
+//
+//	str - name of one of OutputFormats() like "raw-24khz-16bit-mono-pcm" or file extension like ".mp3" or "mp3"
+//
+// Returns:
+//
+//	output format
+//	error if format is unknown
+func ParseOutputFormat(str string) (OutputFormat, error) {
+	return tts.ParseOutputFormat(str)
+}
+
 // GetSoundIter generate speech and return it in iterator with small byte buffers as they come from server.
+// Long text is split into chunks synthesized in separate requests, see WithConcurrency() and WithChunkSize().
+// For WAV formats the first buffer is RIFF header with maximum possible size because final size is not known yet.
+// Header is yielded together with the first sound data, so failed connection is reported before any data.
 //
 // Parameters:
 //
@@ -59,16 +187,21 @@ func (s *Speaker) GetSoundIter(ctx context.Context, format OutputFormat) iter.Se
 			return
 		}
 
-		conn, err := tts.SendRequest(ctx, s.text, s.args, format)
-		if err != nil {
-			yield(nil, err)
-			return
-		}
-
 		capacity := getWordsCount(s.text)
 		metadata := make([]SpeechMetadata, 0, capacity)
 
-		for chunk, err := range tts.ReadResponse(conn) {
+		// WAV header waits for the first sound data
+		header := format.IsWav()
+
+		var turns int
+		var requestIDs []string
+
+		var pacer *pacer
+		if s.pacing {
+			pacer = newPacer(ctx, format, s.lead, s.onWord)
+		}
+
+		for chunk, err := range s.chunks(ctx, format) {
 			if err != nil {
 				yield(nil, err)
 				return
@@ -76,15 +209,57 @@ func (s *Speaker) GetSoundIter(ctx context.Context, format OutputFormat) iter.Se
 
 			switch chunk.ChunkType {
 			case tts.ChunkTypeAudio:
+				if pacer != nil {
+					if err := pacer.audio(chunk.Data); err != nil {
+						yield(nil, err)
+						return
+					}
+				}
+
+				if header {
+					header = false
+
+					if !yield(tts.WavHeader(format, -1), nil) {
+						return
+					}
+				}
+
 				if !yield(chunk.Data, nil) {
 					return
 				}
 			case tts.ChunkTypeWordBoundary:
 				metadata = append(metadata, chunk.Metadata)
+
+				if pacer != nil {
+					pacer.word(chunk.Metadata)
+				} else if s.onWord != nil {
+					s.onWord(chunk.Metadata)
+				}
+			case tts.ChunkTypeEnd:
+				turns++
+
+				// cached turns have no request ID
+				if chunk.RequestID != "" {
+					requestIDs = append(requestIDs, chunk.RequestID)
+				}
+			}
+		}
+
+		if pacer != nil {
+			if err := pacer.finish(); err != nil {
+				yield(nil, err)
+				return
 			}
 		}
 
+		// sound without data is still valid WAV file
+		if header && !yield(tts.WavHeader(format, -1), nil) {
+			return
+		}
+
 		s.metadata = metadata
+		s.turns = turns
+		s.requestIDs = requestIDs
 		s.ready = true
 	}
 }
@@ -116,16 +291,23 @@ func (s *Speaker) GetSound(ctx context.Context, format OutputFormat) ([]byte, er
 		result = append(result, data...)
 	}
 
+	if format.IsWav() && len(result) >= tts.WavHeaderSize {
+		tts.SetWavSizes(result, int64(len(result)-tts.WavHeaderSize))
+	}
+
 	return result, nil
 }
 
 // SaveToFile generate speach and save it to file.
+// Sound is written to temporary file in the same directory which replaces target file only on success,
+// so failed or cancelled generation never leaves truncated file. Use WithFileMode() to set file mode, default is 0644.
 //
 // Parameters:
 //
 //	ctx - context to stop operation before it finished
 //	filename - full path to file. Should be write accessible
 //	format - format of sound data to write to file. Use one of OutputFormat* constants
+//	  or OutputFormatAuto to infer format from file extension
 //
 // Returns:
 //
@@ -135,23 +317,86 @@ func (s *Speaker) SaveToFile(ctx context.Context, filename string, format Output
 		return err
 	}
 
-	file, err := createFile(filename)
+	if format == OutputFormatAuto {
+		var ok bool
+		if format, ok = tts.FormatFromFilename(filename); !ok {
+			return fmt.Errorf("cannot infer output format from extension of '%s'", filename)
+		}
+	}
+
+	file, err := createTempFile(filename)
 	if err != nil {
 		return err
 	}
-	defer file.Close()
 
+	// remove temporary file if it was not renamed to target file
+	renamed := false
+	defer func() {
+		if !renamed {
+			file.Close()
+			os.Remove(file.Name())
+		}
+	}()
+
+	var size int64
 	for data, err := range s.GetSoundIter(ctx, format) {
 		if err != nil {
 			return err
 		}
 
-		if _, err := file.Write(data); err != nil {
+		n, err := file.Write(data)
+		if err != nil {
 			return err
 		}
+
+		size += int64(n)
 	}
 
-	return nil
+	// replace streaming header sizes with actual ones
+	if format.IsWav() && size >= tts.WavHeaderSize {
+		if err := tts.PatchWavSizes(file, size-tts.WavHeaderSize); err != nil {
+			return err
+		}
+	}
+
+	mode := s.fileMode
+	if mode == 0 {
+		mode = defaultFileMode
+	}
+
+	if err := file.Chmod(mode); err != nil {
+		return err
+	}
+
+	if err := file.Sync(); err != nil {
+		return err
+	}
+
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Rename(file.Name(), filename); err != nil {
+		return fmt.Errorf("failed to rename '%s' to '%s': %v", file.Name(), filename, err)
+	}
+	renamed = true
+
+	// make rename durable
+	return syncDir(filepath.Dir(filename))
+}
+
+// WithFileMode sets mode of file created by SaveToFile().
+//
+// Parameters:
+//
+//	mode - file permissions, default is 0644
+//
+// Returns:
+//
+//	the same speaker to chain calls
+func (s *Speaker) WithFileMode(mode os.FileMode) *Speaker {
+	s.fileMode = mode
+	return s
 }
 
 // GetMetadata gets metadata of generated speech
@@ -183,47 +428,47 @@ func getBytesCount(text string, format OutputFormat) int {
 	// duration of 1000 symbols is approximately 100 seconds so 10 symbols can be spoken in 1 second
 	const symbolsPerSecond = 10
 
-	durationSec := len(text) / symbolsPerSecond
-	bytesPerSec := getBytesPerSecond(format)
-
-	return durationSec * bytesPerSec
+	return len(text) * getBytesPerSecond(format) / symbolsPerSecond
 }
 
 func getBytesPerSecond(format OutputFormat) int {
-	switch format {
-	case OutputFormatWebm:
-		return 24_000 / 8 // 24k btrate
-	case OutputFormatRaw22050:
-		return 22_050 * 16 / 8 // 22050 hz, 16 bit
-	case OutputFormatRaw44100:
-		return 44_100 * 16 / 8 // 44100 hz, 16 bit
-	case OutputFormatMp3:
-		fallthrough
-	case OutputFormatOgg:
-		fallthrough
-	default:
-		return 48_000 / 8 // 48k btrate
-	}
+	return format.BytesPerSecond()
 }
 
-func createFile(path string) (*os.File, error) {
-	// create directory
-	if _, err := os.Stat(path); os.IsNotExist(err) {
-		dir := filepath.Dir(path)
+// createTempFile creates directories of path and temporary file in the same directory as path
+func createTempFile(path string) (*os.File, error) {
+	dir := filepath.Dir(path)
 
-		err := os.MkdirAll(dir, 0755)
-		if err != nil {
-			return nil, fmt.Errorf("failed to create dir '%s': %v", dir, err)
-		}
-	} else if err != nil {
-		return nil, err
+	// create directory, permissions are restricted by umask
+	if err := os.MkdirAll(dir, 0777); err != nil {
+		return nil, fmt.Errorf("failed to create dir '%s': %v", dir, err)
 	}
 
 	// open file
-	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_TRUNC|os.O_CREATE, os.ModePerm)
+	file, err := os.CreateTemp(dir, "."+filepath.Base(path)+".*.tmp")
 	if err != nil {
-		return nil, fmt.Errorf("failed to open file '%s': %v", path, err)
+		return nil, fmt.Errorf("failed to create temporary file for '%s': %v", path, err)
 	}
 
 	return file, nil
 }
+
+// syncDir flushes directory entries to disk, so renamed file survives system crash
+func syncDir(path string) error {
+	// directory handles can't be flushed on windows
+	if runtime.GOOS == "windows" {
+		return nil
+	}
+
+	dir, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open dir '%s': %v", path, err)
+	}
+	defer dir.Close()
+
+	if err := dir.Sync(); err != nil {
+		return fmt.Errorf("failed to sync dir '%s': %v", path, err)
+	}
+
+	return nil
+}
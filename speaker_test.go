@@ -0,0 +1,155 @@
+package edgetts
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func Test_SaveToFile(t *testing.T) {
+	tests := []struct {
+		name     string
+		filename string
+		mode     os.FileMode
+		wantMode os.FileMode
+	}{
+		{
+			name:     "default mode",
+			filename: "speech.pcm",
+			wantMode: defaultFileMode,
+		},
+		{
+			name:     "custom mode",
+			filename: "speech.pcm",
+			mode:     0600,
+			wantMode: 0600,
+		},
+		{
+			name:     "create directory",
+			filename: filepath.Join("sub", "dir", "speech.pcm"),
+			wantMode: defaultFileMode,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newEdgeServer(t)
+			dir := t.TempDir()
+			filename := filepath.Join(dir, tt.filename)
+
+			speaker := newTestEdgeTTS(server).Speak("Hello world")
+			if tt.mode != 0 {
+				speaker.WithFileMode(tt.mode)
+			}
+
+			if err := speaker.SaveToFile(t.Context(), filename, OutputFormatAuto); err != nil {
+				t.Fatalf("expected error to be 'nil', but got '%v'", err)
+			}
+
+			data, err := os.ReadFile(filename)
+			if err != nil {
+				t.Fatalf("expected error to be 'nil', but got '%v'", err)
+			}
+
+			if string(data) != "Hello world" {
+				t.Errorf("expected file to contain 'Hello world', but got '%s'", data)
+			}
+
+			info, err := os.Stat(filename)
+			if err != nil {
+				t.Fatalf("expected error to be 'nil', but got '%v'", err)
+			}
+
+			if runtime.GOOS != "windows" && info.Mode().Perm() != tt.wantMode {
+				t.Errorf("expected file mode to be '%v', but got '%v'", tt.wantMode, info.Mode().Perm())
+			}
+
+			assertNoTempFiles(t, filepath.Dir(filename))
+		})
+	}
+}
+
+func Test_SaveToFileFailure(t *testing.T) {
+	tests := []struct {
+		name     string
+		filename string
+		voice    string
+		cancel   bool
+	}{
+		{
+			name:     "synthesis failed",
+			filename: "speech.pcm",
+			voice:    "wrong",
+		},
+		{
+			name:     "cancelled",
+			filename: "speech.pcm",
+			voice:    testVoice,
+			cancel:   true,
+		},
+		{
+			name:     "unknown extension",
+			filename: "speech.txt",
+			voice:    testVoice,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newEdgeServer(t)
+			dir := t.TempDir()
+			filename := filepath.Join(dir, tt.filename)
+
+			if err := os.WriteFile(filename, []byte("previous"), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			ctx, cancel := context.WithCancel(t.Context())
+			defer cancel()
+
+			if tt.cancel {
+				// cancel synthesis when server receives request and never answer it
+				server.setRespond(func(text string) bool {
+					cancel()
+					return false
+				})
+			}
+
+			etts := New(Args{Voice: tt.voice}, WithEndpoint(server.url))
+
+			if err := etts.Speak("Hello world").SaveToFile(ctx, filename, OutputFormatAuto); err == nil {
+				t.Fatal("expected error, but got 'nil'")
+			}
+
+			data, err := os.ReadFile(filename)
+			if err != nil {
+				t.Fatalf("expected error to be 'nil', but got '%v'", err)
+			}
+
+			if string(data) != "previous" {
+				t.Errorf("expected file to be untouched, but got '%s'", data)
+			}
+
+			assertNoTempFiles(t, dir)
+		})
+	}
+}
+
+// assertNoTempFiles checks that SaveToFile removed its temporary files
+func assertNoTempFiles(t *testing.T, dir string) {
+	t.Helper()
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, entry := range entries {
+		if strings.HasSuffix(entry.Name(), ".tmp") {
+			t.Errorf("expected temporary file '%s' to be removed", entry.Name())
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package edgetts
+
+import (
+	"context"
+	"io"
+)
+
+// soundReader reads sound data generated in background goroutine
+type soundReader struct {
+	*io.PipeReader
+	cancel context.CancelFunc
+}
+
+// Close stops speech generation and closes connection to Edge TTS server
+func (r *soundReader) Close() error {
+	r.cancel()
+	return r.PipeReader.Close()
+}
+
+// SoundWriter generates speech in fixed format within fixed context. Implements io.WriterTo.
+// Create it with Speaker.Writer()
+type SoundWriter struct {
+	speaker *Speaker
+	ctx     context.Context
+	format  OutputFormat
+}
+
+// Writer returns io.WriterTo which generates speech in format within ctx and writes it to destination writer.
+//
+// Parameters:
+//
+//	ctx - context to stop operation before it finished
+//	format - format of sound data. Use one of OutputFormat* constants
+//
+// Returns:
+//
+//	writer of sound data
+func (s *Speaker) Writer(ctx context.Context, format OutputFormat) SoundWriter {
+	return SoundWriter{
+		speaker: s,
+		ctx:     ctx,
+		format:  format,
+	}
+}
+
+// WriteTo generate speech in mp3 format and write it to w. Implements io.WriterTo.
+// Use Writer() to set format and context.
+//
+// Parameters:
+//
+//	w - writer to write sound data to, e.g. http.ResponseWriter
+//
+// Returns:
+//
+//	count of written bytes
+//	error if generation, data transferring or write failed
+func (s *Speaker) WriteTo(w io.Writer) (int64, error) {
+	return s.Writer(context.Background(), OutputFormatMp3).WriteTo(w)
+}
+
+// WriteTo generate speech and write it to w.
+//
+// Parameters:
+//
+//	w - writer to write sound data to, e.g. http.ResponseWriter
+//
+// Returns:
+//
+//	count of written bytes
+//	error if generation, data transferring or write failed
+func (sw SoundWriter) WriteTo(w io.Writer) (int64, error) {
+	var written int64
+	for data, err := range sw.speaker.GetSoundIter(sw.ctx, sw.format) {
+		if err != nil {
+			return written, err
+		}
+
+		n, err := w.Write(data)
+		written += int64(n)
+		if err != nil {
+			return written, err
+		}
+	}
+
+	return written, nil
+}
+
+// Open generate speech and return reader to read sound data as it comes from server.
+// Closing the reader closes connection to Edge TTS server.
+//
+// Parameters:
+//
+//	ctx - context to stop operation before it finished
+//	format - format of sound data. Use one of OutputFormat* constants
+//
+// Returns:
+//
+//	reader of sound data. Should be closed after use
+//	error if connection to server failed
+func (s *Speaker) Open(ctx context.Context, format OutputFormat) (io.ReadCloser, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	pr, pw := io.Pipe()
+
+	// receives nil after first sound data or error if generation failed before
+	started := make(chan error, 1)
+
+	go func() {
+		first := true
+
+		for data, err := range s.GetSoundIter(ctx, format) {
+			if first {
+				first = false
+				started <- err
+			}
+
+			if err != nil {
+				pw.CloseWithError(err)
+				return
+			}
+
+			if _, err := pw.Write(data); err != nil {
+				return
+			}
+		}
+
+		if first {
+			started <- nil
+		}
+
+		pw.Close()
+	}()
+
+	if err := <-started; err != nil {
+		cancel()
+		return nil, err
+	}
+
+	return &soundReader{
+		PipeReader: pr,
+		cancel:     cancel,
+	}, nil
+}
@@ -0,0 +1,187 @@
+package edgetts
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/kolonist/edgetts/internal/tts"
+)
+
+func Test_WriteTo(t *testing.T) {
+	tests := []struct {
+		name    string
+		write   func(speaker *Speaker, w io.Writer) (int64, error)
+		formats []string
+	}{
+		{
+			name: "speaker writes mp3",
+			write: func(speaker *Speaker, w io.Writer) (int64, error) {
+				return speaker.WriteTo(w)
+			},
+			formats: []string{OutputFormatMp3.String()},
+		},
+		{
+			name: "writer with format",
+			write: func(speaker *Speaker, w io.Writer) (int64, error) {
+				return speaker.Writer(context.Background(), OutputFormatRaw24000).WriteTo(w)
+			},
+			formats: []string{OutputFormatRaw24000.String()},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newEdgeServer(t)
+
+			var buf bytes.Buffer
+			n, err := tt.write(newTestEdgeTTS(server).Speak("Hello world"), &buf)
+			if err != nil {
+				t.Fatalf("expected error to be 'nil', but got '%v'", err)
+			}
+
+			if buf.String() != "Hello world" || n != int64(buf.Len()) {
+				t.Errorf("expected to write 'Hello world', but wrote %d bytes '%s'", n, buf.String())
+			}
+
+			if _, formats, _ := server.stats(); !slices.Equal(formats, tt.formats) {
+				t.Errorf("expected formats to be '%v', but got '%v'", tt.formats, formats)
+			}
+		})
+	}
+}
+
+func Test_WriterContext(t *testing.T) {
+	server := newEdgeServer(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var buf bytes.Buffer
+	_, err := newTestEdgeTTS(server).Speak("Hello world").Writer(ctx, OutputFormatRaw24000).WriteTo(&buf)
+	if err != context.Canceled {
+		t.Errorf("expected error to be '%v', but got '%v'", context.Canceled, err)
+	}
+
+	if buf.Len() != 0 {
+		t.Errorf("expected nothing to be written, but got '%s'", buf.String())
+	}
+}
+
+func Test_Open(t *testing.T) {
+	t.Run("read all", func(t *testing.T) {
+		server := newEdgeServer(t)
+
+		reader, err := newTestEdgeTTS(server).Speak("Hello world").Open(t.Context(), OutputFormatRaw24000)
+		if err != nil {
+			t.Fatalf("expected error to be 'nil', but got '%v'", err)
+		}
+		defer reader.Close()
+
+		data, err := io.ReadAll(reader)
+		if err != nil {
+			t.Fatalf("expected error to be 'nil', but got '%v'", err)
+		}
+
+		if string(data) != "Hello world" {
+			t.Errorf("expected sound to be 'Hello world', but got '%s'", data)
+		}
+	})
+
+	t.Run("error before first byte", func(t *testing.T) {
+		server := newEdgeServer(t)
+
+		etts := New(Args{Voice: "wrong"}, WithEndpoint(server.url))
+
+		reader, err := etts.Speak("Hello world").Open(t.Context(), OutputFormatRaw24000)
+		if err == nil {
+			t.Error("expected error, but got 'nil'")
+		}
+
+		if reader != nil {
+			t.Error("expected reader to be 'nil'")
+		}
+
+		if connections, _, _ := server.stats(); connections != 0 {
+			t.Errorf("expected no connections, but got %d", connections)
+		}
+	})
+
+	t.Run("wav header with first sound data", func(t *testing.T) {
+		server := newEdgeServer(t)
+
+		reader, err := newTestEdgeTTS(server).Speak("Hello world").Open(t.Context(), OutputFormatWav24000)
+		if err != nil {
+			t.Fatalf("expected error to be 'nil', but got '%v'", err)
+		}
+		defer reader.Close()
+
+		data, err := io.ReadAll(reader)
+		if err != nil {
+			t.Fatalf("expected error to be 'nil', but got '%v'", err)
+		}
+
+		if len(data) != tts.WavHeaderSize+len("Hello world") || string(data[:4]) != "RIFF" {
+			t.Errorf("expected WAV header followed by sound, but got '%q'", data)
+		}
+	})
+
+	t.Run("wav server unreachable", func(t *testing.T) {
+		// nothing listens on closed server
+		server := httptest.NewServer(http.NotFoundHandler())
+		server.Close()
+
+		etts := New(Args{Voice: testVoice}, WithEndpoint("ws"+strings.TrimPrefix(server.URL, "http")))
+
+		reader, err := etts.Speak("Hello world").Open(t.Context(), OutputFormatWav24000)
+		if err == nil {
+			reader.Close()
+			t.Error("expected connection error, but got 'nil'")
+		}
+	})
+
+	t.Run("close cancels generation", func(t *testing.T) {
+		server := newEdgeServer(t)
+
+		// the second chunk is never answered, so generation is stopped only by Close()
+		unanswered := make(chan struct{})
+		server.setRespond(func(text string) bool {
+			if strings.Contains(text, "Second") {
+				close(unanswered)
+				return false
+			}
+
+			return true
+		})
+
+		speaker := newTestEdgeTTS(server).Speak("First sentence. Second sentence.").WithChunkSize(20)
+
+		reader, err := speaker.Open(t.Context(), OutputFormatRaw24000)
+		if err != nil {
+			t.Fatalf("expected error to be 'nil', but got '%v'", err)
+		}
+
+		data := make([]byte, len("First sentence."))
+		if _, err := io.ReadFull(reader, data); err != nil {
+			t.Fatalf("expected error to be 'nil', but got '%v'", err)
+		}
+
+		<-unanswered
+
+		if err := reader.Close(); err != nil {
+			t.Errorf("expected error to be 'nil', but got '%v'", err)
+		}
+
+		// connection of the first chunk is closed after its turn, connection of the second one is closed by Close()
+		server.waitDisconnected(t, 2)
+
+		if _, err := reader.Read(data); err != io.ErrClosedPipe {
+			t.Errorf("expected error to be '%v', but got '%v'", io.ErrClosedPipe, err)
+		}
+	})
+}
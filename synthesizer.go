@@ -0,0 +1,129 @@
+package edgetts
+
+import (
+	"context"
+	"io"
+)
+
+// Synthesizer generates speech from text. It is implemented by EdgeTTS, Registry and CommandSynthesizer,
+// so code can depend on the interface and swap engines or use mock in tests
+type Synthesizer interface {
+	// Synthesize starts speech generation and returns stream of sound data.
+	// Error is returned if generation failed before the first sound data.
+	Synthesize(ctx context.Context, request SynthesisRequest) (AudioStream, error)
+}
+
+// SynthesisRequest contains text and parameters of speech synthesis
+type SynthesisRequest struct {
+	// Text to speak or SSML document if Args.SSML is true
+	Text string
+
+	// Parameters of speech like voice, rate, volume. Empty fields are taken from synthesizer defaults
+	Args Args
+
+	// Format of sound data. Use one of OutputFormat* constants
+	Format OutputFormat
+}
+
+// AudioStream is stream of synthesized sound data
+type AudioStream interface {
+	io.ReadCloser
+
+	// Metadata returns timings of words. It is available after stream is read till EOF.
+	// Synthesizers without word boundaries return nil
+	Metadata() []SpeechMetadata
+}
+
+// speakerStream is sound data of speaker
+type speakerStream struct {
+	io.ReadCloser
+	speaker *Speaker
+
+	// true after EOF, when speaker finished synthesis
+	done bool
+}
+
+func (s *speakerStream) Read(p []byte) (int, error) {
+	n, err := s.ReadCloser.Read(p)
+	if err == io.EOF {
+		s.done = true
+	}
+
+	return n, err
+}
+
+// Metadata returns timings of words after speech synthesis finished
+func (s *speakerStream) Metadata() []SpeechMetadata {
+	if !s.done {
+		return nil
+	}
+
+	metadata, _ := s.speaker.GetMetadata()
+	return metadata
+}
+
+// SpeakSSML define SSML document you need to convert to speech. Document is sent to Edge TTS server as is,
+// so voice, rate, volume and pitch should be set in it.
+//
+// Parameters:
+//
+//	ssml - SSML document with <speak> root element
+//
+// Returns:
+//
+//	speaker struct to use get synthesized sound
+func (etts *EdgeTTS) SpeakSSML(ssml string) *Speaker {
+	speaker := etts.Speak(ssml)
+	speaker.args.SSML = true
+
+	return speaker
+}
+
+// Synthesize implements Synthesizer. Empty fields of request args are taken from args passed to New().
+//
+// Parameters:
+//
+//	ctx - context to stop operation before it finished
+//	request - text and parameters of speech
+//
+// Returns:
+//
+//	stream of sound data. Should be closed after use
+//	error if generation failed before the first sound data
+func (etts *EdgeTTS) Synthesize(ctx context.Context, request SynthesisRequest) (AudioStream, error) {
+	speaker := etts.Speak(request.Text)
+	speaker.args = mergeArgs(request.Args, etts.args)
+
+	reader, err := speaker.Open(ctx, request.Format)
+	if err != nil {
+		return nil, err
+	}
+
+	stream := &speakerStream{
+		ReadCloser: reader,
+		speaker:    speaker,
+	}
+
+	return stream, nil
+}
+
+// mergeArgs fills empty fields of args with defaults
+func mergeArgs(args Args, defaults Args) Args {
+	if args.Voice == "" {
+		args.Voice = defaults.Voice
+	}
+
+	if args.Volume == "" {
+		args.Volume = defaults.Volume
+	}
+
+	if args.Rate == "" {
+		args.Rate = defaults.Rate
+	}
+
+	if args.Pitch == "" {
+		args.Pitch = defaults.Pitch
+	}
+
+	return args
+}
@@ -0,0 +1,411 @@
+package edgetts
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"strings"
+	"sync"
+
+	"github.com/kolonist/edgetts/internal/tts"
+)
+
+// defaultMaxSegmentSize is size of buffered text in bytes which is synthesized even if sentence is not finished
+const defaultMaxSegmentSize = 500
+
+// TextStream synthesizes text which comes in pieces, e.g. tokens generated by LLM.
+// Text is buffered until there is complete sentence or buffer exceeds maximum segment size,
+// then segment is synthesized and its sound is appended to one continuous audio stream.
+// Webm and ogg formats are not supported because their sound can't be joined from several responses.
+type TextStream struct {
+	etts           *EdgeTTS
+	args           Args
+	format         OutputFormat
+	maxSegmentSize int
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	start  sync.Once
+
+	// text input state, guarded by mu
+	mu      sync.Mutex
+	cond    *sync.Cond
+	pending strings.Builder
+	queue   []string
+	closed  bool
+
+	// sound data, closed when synthesis finished
+	audio chan chunkResult
+
+	// written by synthesis goroutine before audio is closed
+	metadata []SpeechMetadata
+	finished bool
+}
+
+// NewTextStream creates stream to synthesize text which comes in pieces.
+// Write text to stream with Write() or WriteString(), call Close() when text ends and read sound with Audio().
+//
+// Parameters:
+//
+//	ctx - context to stop operation before it finished
+//	format - format of sound data. Use one of OutputFormat* constants except webm and ogg ones,
+//	  sound of unsupported format fails with ErrUnsupportedFormat
+//
+// Returns:
+//
+//	text stream
+func (etts *EdgeTTS) NewTextStream(ctx context.Context, format OutputFormat) *TextStream {
+	ctx, cancel := context.WithCancel(ctx)
+
+	stream := &TextStream{
+		etts:           etts,
+		args:           etts.args,
+		format:         format,
+		maxSegmentSize: defaultMaxSegmentSize,
+		ctx:            ctx,
+		cancel:         cancel,
+		audio:          make(chan chunkResult, chunkBufferSize),
+	}
+	stream.cond = sync.NewCond(&stream.mu)
+
+	return stream
+}
+
+// SpeakStream synthesizes text which comes in pieces as one continuous audio stream.
+//
+// Parameters:
+//
+//	ctx - context to stop operation before it finished
+//	texts - pieces of text, e.g. tokens generated by LLM. It is consumed in separate goroutine
+//	format - format of sound data. Use one of OutputFormat* constants except webm and ogg ones
+//
+// Returns:
+//
+//	iterator of sound data
+func (etts *EdgeTTS) SpeakStream(ctx context.Context, texts iter.Seq[string], format OutputFormat) iter.Seq2[[]byte, error] {
+	return func(yield func([]byte, error) bool) {
+		stream := etts.NewTextStream(ctx, format)
+
+		go func() {
+			defer stream.Close()
+
+			for text := range texts {
+				if _, err := stream.WriteString(text); err != nil {
+					return
+				}
+			}
+		}()
+
+		for data, err := range stream.Audio() {
+			if !yield(data, err) {
+				return
+			}
+		}
+	}
+}
+
+// WithMaxSegmentSize sets size of buffered text which is synthesized even if sentence is not finished.
+//
+// Parameters:
+//
+//	size - size in bytes, default is 500
+//
+// Returns:
+//
+//	the same stream to chain calls
+func (s *TextStream) WithMaxSegmentSize(size int) *TextStream {
+	s.maxSegmentSize = size
+	return s
+}
+
+// WithVoice sets voice to speak text with instead of voice from EdgeTTS args.
+//
+// Parameters:
+//
+//	voice - voice of speaker to read text
+//
+// Returns:
+//
+//	the same stream to chain calls
+func (s *TextStream) WithVoice(voice string) *TextStream {
+	s.args.Voice = voice
+	return s
+}
+
+// Write appends text to stream. Implements io.Writer.
+//
+// Parameters:
+//
+//	p - piece of text
+//
+// Returns:
+//
+//	count of written bytes
+//	error if stream is closed
+func (s *TextStream) Write(p []byte) (int, error) {
+	return s.WriteString(string(p))
+}
+
+// WriteString appends text to stream. Implements io.StringWriter.
+//
+// Parameters:
+//
+//	text - piece of text
+//
+// Returns:
+//
+//	count of written bytes
+//	error if stream is closed
+func (s *TextStream) WriteString(text string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return 0, fmt.Errorf("text stream is closed")
+	}
+
+	if err := s.ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	s.pending.WriteString(text)
+
+	// move all complete segments to queue
+	for {
+		pending := s.pending.String()
+
+		pos := tts.SegmentEnd(pending, s.maxSegmentSize)
+		if pos == 0 {
+			break
+		}
+
+		s.enqueue(pending[:pos])
+
+		s.pending.Reset()
+		s.pending.WriteString(pending[pos:])
+	}
+
+	s.startSynthesis()
+
+	return len(text), nil
+}
+
+// Flush forces synthesis of buffered text even if sentence is not finished.
+//
+// Returns:
+//
+//	error if stream is closed
+func (s *TextStream) Flush() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return fmt.Errorf("text stream is closed")
+	}
+
+	s.flush()
+	s.startSynthesis()
+
+	return nil
+}
+
+// Close flushes buffered text and ends text input. Sound stream ends after the last segment is synthesized.
+//
+// Returns:
+//
+//	always nil
+func (s *TextStream) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return nil
+	}
+
+	s.flush()
+	s.closed = true
+	s.cond.Broadcast()
+	s.startSynthesis()
+
+	return nil
+}
+
+// Audio returns sound data of all segments as one continuous stream. It should be read simultaneously
+// with text writing, otherwise synthesis stops when internal buffer is full.
+// Breaking the loop stops synthesis.
+//
+// Returns:
+//
+//	iterator of sound data
+func (s *TextStream) Audio() iter.Seq2[[]byte, error] {
+	return func(yield func([]byte, error) bool) {
+		s.mu.Lock()
+		s.startSynthesis()
+		s.mu.Unlock()
+
+		for result := range s.audio {
+			if !yield(result.chunk.Data, result.err) || result.err != nil {
+				s.cancel()
+				return
+			}
+		}
+
+		if !s.finished {
+			yield(nil, s.ctx.Err())
+		}
+	}
+}
+
+// Metadata gets metadata of synthesized speech with offsets from the start of whole stream.
+//
+// Returns:
+//
+//	slice with SpeechMetadata structs containing timings of each word in text
+//	error if there is no metadata because synthesys has not finished yet
+func (s *TextStream) Metadata() ([]SpeechMetadata, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.finished {
+		return s.metadata, nil
+	}
+
+	return nil, fmt.Errorf("speech synthesys not finished, first read all sound with Audio() method")
+}
+
+// flush moves pending text to queue. Should be called with s.mu locked
+func (s *TextStream) flush() {
+	s.enqueue(s.pending.String())
+	s.pending.Reset()
+}
+
+// enqueue adds segment to synthesis queue. Should be called with s.mu locked
+func (s *TextStream) enqueue(segment string) {
+	if strings.TrimSpace(segment) == "" {
+		return
+	}
+
+	s.queue = append(s.queue, segment)
+	s.cond.Broadcast()
+}
+
+// startSynthesis starts synthesis goroutine once. Should be called with s.mu locked
+func (s *TextStream) startSynthesis() {
+	s.start.Do(func() {
+		// wake up synthesis goroutine waiting for text on cancellation
+		context.AfterFunc(s.ctx, func() {
+			s.mu.Lock()
+			s.cond.Broadcast()
+			s.mu.Unlock()
+		})
+
+		go s.synthesize()
+	})
+}
+
+// next waits for next segment. Returns false if stream is closed and all segments are synthesized
+func (s *TextStream) next() (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for len(s.queue) == 0 && !s.closed && s.ctx.Err() == nil {
+		s.cond.Wait()
+	}
+
+	if len(s.queue) == 0 || s.ctx.Err() != nil {
+		return "", false
+	}
+
+	segment := s.queue[0]
+	s.queue = s.queue[1:]
+
+	return segment, true
+}
+
+// send passes result to Audio() consumer. Returns false if stream is cancelled
+func (s *TextStream) send(result chunkResult) bool {
+	select {
+	case s.audio <- result:
+		return true
+	case <-s.ctx.Done():
+		return false
+	}
+}
+
+// synthesize synthesizes segments one after another and sends sound to audio channel
+func (s *TextStream) synthesize() {
+	defer s.cancel()
+	defer close(s.audio)
+
+	if s.format.IsContainer() {
+		s.send(chunkResult{err: fmt.Errorf("%w: sound of %s can't be joined from segments", ErrUnsupportedFormat, s.format)})
+		return
+	}
+
+	// WAV header waits for the first sound data, so failed connection is reported before any data
+	header := s.format.IsWav()
+
+	sendHeader := func() bool {
+		header = false
+
+		chunk := tts.ResponseChunk{
+			ChunkType: tts.ChunkTypeAudio,
+			Data:      tts.WavHeader(s.format, -1),
+		}
+
+		return s.send(chunkResult{chunk: chunk})
+	}
+
+	var metadata []SpeechMetadata
+
+	// duration of sound data of all previous segments
+	timer := tts.NewAudioTimer(s.format)
+
+	for {
+		segment, ok := s.next()
+		if !ok {
+			break
+		}
+
+		offset := timer.Total()
+
+		speaker := s.etts.Speak(segment)
+		speaker.args = s.args
+
+		for chunk, err := range speaker.chunks(s.ctx, s.format) {
+			if err != nil {
+				s.send(chunkResult{err: err})
+				return
+			}
+
+			switch chunk.ChunkType {
+			case tts.ChunkTypeAudio:
+				timer.Duration(chunk.Data)
+
+				if header && !sendHeader() {
+					return
+				}
+
+				if !s.send(chunkResult{chunk: chunk}) {
+					return
+				}
+			case tts.ChunkTypeWordBoundary:
+				chunk.Metadata = chunk.Metadata.Shift(offset)
+				metadata = append(metadata, chunk.Metadata)
+			}
+		}
+	}
+
+	if s.ctx.Err() != nil {
+		return
+	}
+
+	if header && !sendHeader() {
+		return
+	}
+
+	s.mu.Lock()
+	s.metadata = metadata
+	s.finished = true
+	s.mu.Unlock()
+}
@@ -0,0 +1,195 @@
+package edgetts
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_TextStream(t *testing.T) {
+	tests := []struct {
+		name           string
+		maxSegmentSize int
+
+		// pieces of text, empty piece means Flush()
+		writes   []string
+		segments []string
+	}{
+		{
+			name:     "sentences",
+			writes:   []string{"Hello wor", "ld. How are", " you? Fine"},
+			segments: []string{"Hello world. ", "How are you? ", "Fine"},
+		},
+		{
+			name:           "max segment size",
+			maxSegmentSize: 10,
+			writes:         []string{"one two three", " four five six"},
+			segments:       []string{"one two ", "three ", "four five ", "six"},
+		},
+		{
+			name:     "flush",
+			writes:   []string{"Hello", "", " world", ""},
+			segments: []string{"Hello", " world"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newEdgeServer(t)
+
+			stream := newTestEdgeTTS(server).NewTextStream(t.Context(), OutputFormatRaw24000)
+			if tt.maxSegmentSize > 0 {
+				stream.WithMaxSegmentSize(tt.maxSegmentSize)
+			}
+
+			var text strings.Builder
+			for _, piece := range tt.writes {
+				if piece == "" {
+					if err := stream.Flush(); err != nil {
+						t.Fatalf("expected error to be 'nil', but got '%v'", err)
+					}
+
+					continue
+				}
+
+				text.WriteString(piece)
+				if _, err := stream.Write([]byte(piece)); err != nil {
+					t.Fatalf("expected error to be 'nil', but got '%v'", err)
+				}
+			}
+
+			stream.Close()
+
+			var sound []byte
+			for data, err := range stream.Audio() {
+				if err != nil {
+					t.Fatalf("expected error to be 'nil', but got '%v'", err)
+				}
+
+				sound = append(sound, data...)
+			}
+
+			if string(sound) != text.String() {
+				t.Errorf("expected sound to be '%s', but got '%s'", text.String(), sound)
+			}
+
+			if _, _, texts := server.stats(); !slices.Equal(texts, tt.segments) {
+				t.Errorf("expected segments to be '%q', but got '%q'", tt.segments, texts)
+			}
+		})
+	}
+}
+
+func Test_TextStreamMetadata(t *testing.T) {
+	server := newEdgeServer(t)
+
+	stream := newTestEdgeTTS(server).NewTextStream(t.Context(), OutputFormatRaw24000)
+
+	if _, err := stream.Metadata(); err == nil {
+		t.Error("expected error before synthesis finished, but got 'nil'")
+	}
+
+	stream.WriteString("One two. Three four.")
+	stream.Close()
+
+	for _, err := range stream.Audio() {
+		if err != nil {
+			t.Fatalf("expected error to be 'nil', but got '%v'", err)
+		}
+	}
+
+	metadata, err := stream.Metadata()
+	if err != nil {
+		t.Fatalf("expected error to be 'nil', but got '%v'", err)
+	}
+
+	// the second segment starts after sound of the first one "One two. " in 16 bit 24khz PCM
+	shift := time.Duration(len("One two. ")) * time.Second / time.Duration(OutputFormatRaw24000.BytesPerSecond())
+	word := 100 * time.Millisecond
+
+	want := []time.Duration{0, word, shift, shift + word}
+
+	var starts []time.Duration
+	for _, m := range metadata {
+		starts = append(starts, m.Start)
+	}
+
+	if !slices.Equal(starts, want) {
+		t.Errorf("expected word starts to be '%v', but got '%v'", want, starts)
+	}
+}
+
+func Test_TextStreamClose(t *testing.T) {
+	server := newEdgeServer(t)
+
+	stream := newTestEdgeTTS(server).NewTextStream(t.Context(), OutputFormatRaw24000)
+
+	stream.WriteString("Hello world. Unfinished")
+
+	if err := stream.Close(); err != nil {
+		t.Errorf("expected error to be 'nil', but got '%v'", err)
+	}
+
+	if _, err := stream.WriteString("more"); err == nil {
+		t.Error("expected error of write to closed stream, but got 'nil'")
+	}
+
+	if err := stream.Flush(); err == nil {
+		t.Error("expected error of flush of closed stream, but got 'nil'")
+	}
+
+	// sound of text written before Close() is not lost
+	var sound []byte
+	for data, err := range stream.Audio() {
+		if err != nil {
+			t.Fatalf("expected error to be 'nil', but got '%v'", err)
+		}
+
+		sound = append(sound, data...)
+	}
+
+	if string(sound) != "Hello world. Unfinished" {
+		t.Errorf("expected sound to be 'Hello world. Unfinished', but got '%s'", sound)
+	}
+}
+
+func Test_TextStreamBreak(t *testing.T) {
+	server := newEdgeServer(t)
+
+	stream := newTestEdgeTTS(server).NewTextStream(t.Context(), OutputFormatRaw24000)
+	stream.WriteString("Hello world. ")
+
+	for range stream.Audio() {
+		break
+	}
+
+	// breaking the loop cancels stream
+	for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); time.Sleep(time.Millisecond) {
+		if _, err := stream.WriteString("more"); err != nil {
+			return
+		}
+	}
+
+	t.Error("expected write to cancelled stream to fail")
+}
+
+func Test_TextStreamContainerFormat(t *testing.T) {
+	server := newEdgeServer(t)
+
+	stream := newTestEdgeTTS(server).NewTextStream(t.Context(), OutputFormatWebm)
+	stream.WriteString("Hello world. ")
+	stream.Close()
+
+	var err error
+	for _, err = range stream.Audio() {
+		if err != nil {
+			break
+		}
+	}
+
+	if !errors.Is(err, ErrUnsupportedFormat) {
+		t.Errorf("expected error to be '%v', but got '%v'", ErrUnsupportedFormat, err)
+	}
+}
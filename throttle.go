@@ -0,0 +1,223 @@
+package edgetts
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"time"
+
+	"github.com/kolonist/edgetts/internal/throttle"
+	"github.com/kolonist/edgetts/internal/tts"
+)
+
+// Errors returned in fail fast mode, see WithFailFastLimits()
+var (
+	// ErrRateLimited is returned when request rate limit set with WithRateLimit() is exceeded
+	ErrRateLimited = throttle.ErrRateLimited
+
+	// ErrTooManyConnections is returned when all connections allowed with WithMaxConnections() are in use
+	ErrTooManyConnections = throttle.ErrTooManyConnections
+)
+
+// ErrCircuitOpen is matched with errors.Is() by errors returned while circuit breaker is open, see WithCircuitBreaker()
+var ErrCircuitOpen = throttle.ErrCircuitOpen
+
+// CircuitOpenError is returned while circuit breaker rejects requests after repeated failures of Edge TTS server
+type CircuitOpenError = throttle.CircuitOpenError
+
+// HandshakeError is returned when Edge TTS server rejects websocket handshake, e.g. with status 429 or 403
+type HandshakeError = tts.HandshakeError
+
+// WithRateLimit limits rate of requests to Edge TTS server. Limit is shared by all speakers of EdgeTTS,
+// cache hits and deduplicated requests are not counted.
+//
+// Parameters:
+//
+//	requestsPerSecond - average count of requests per second
+//	burst - count of requests which can be sent at once
+//
+// Returns:
+//
+//	option to pass to New()
+func WithRateLimit(requestsPerSecond float64, burst int) Option {
+	return func(etts *EdgeTTS) {
+		if requestsPerSecond > 0 {
+			etts.limiter = throttle.NewLimiter(requestsPerSecond, burst)
+		}
+	}
+}
+
+// WithMaxConnections limits count of simultaneous websocket connections. Limit is shared by all speakers of EdgeTTS.
+//
+// Parameters:
+//
+//	n - maximum count of open connections, idle and warm connections are counted too
+//
+// Returns:
+//
+//	option to pass to New()
+func WithMaxConnections(n int) Option {
+	return func(etts *EdgeTTS) {
+		if n > 0 {
+			etts.connections = throttle.NewSemaphore(n)
+			etts.adaptive = nil
+		}
+	}
+}
+
+// WithFailFastLimits makes synthesis fail with ErrRateLimited or ErrTooManyConnections immediately
+// instead of waiting when limits are reached.
+//
+// Returns:
+//
+//	option to pass to New()
+func WithFailFastLimits() Option {
+	return func(etts *EdgeTTS) {
+		etts.failFast = true
+	}
+}
+
+// WithCircuitBreaker stops sending requests to Edge TTS server after repeated failures: handshake failures
+// including 429 and 403 statuses, abnormal websocket close codes and network errors. While circuit is open
+// synthesis fails immediately with CircuitOpenError. After cooldown single probe request is sent:
+// its success closes circuit, its failure opens circuit for another cooldown.
+//
+// Parameters:
+//
+//	threshold - count of consecutive failures which opens circuit
+//	cooldown - time during which requests are rejected
+//
+// Returns:
+//
+//	option to pass to New()
+func WithCircuitBreaker(threshold int, cooldown time.Duration) Option {
+	return func(etts *EdgeTTS) {
+		etts.breaker = throttle.NewBreaker(threshold, cooldown)
+	}
+}
+
+// WithAdaptiveConcurrency limits count of simultaneous connections like WithMaxConnections() and adapts limit
+// to Edge TTS server: limit is halved when server throttles requests and grows back by one after
+// enough successful requests. Replaces limit set with WithMaxConnections().
+//
+// Parameters:
+//
+//	maxConnections - maximum count of open connections
+//
+// Returns:
+//
+//	option to pass to New()
+func WithAdaptiveConcurrency(maxConnections int) Option {
+	return func(etts *EdgeTTS) {
+		if maxConnections > 0 {
+			etts.connections = throttle.NewSemaphore(maxConnections)
+			etts.adaptive = throttle.NewAdaptive(etts.connections)
+		}
+	}
+}
+
+// upstream runs synthesis turn on Edge TTS server within rate and connection limits
+func (etts *EdgeTTS) upstream(ctx context.Context, text string, args Args, format OutputFormat) iter.Seq2[tts.ResponseChunk, error] {
+	return func(yield func(tts.ResponseChunk, error) bool) {
+		var probe bool
+		if etts.breaker != nil {
+			var err error
+			if probe, err = etts.breaker.Allow(); err != nil {
+				yield(tts.ResponseChunk{}, err)
+				return
+			}
+		}
+
+		if err := etts.wait(ctx); err != nil {
+			etts.report(probe, false, nil)
+			yield(tts.ResponseChunk{}, err)
+			return
+		}
+
+		done := false
+		var turnErr error
+
+		defer func() {
+			etts.report(probe, done, turnErr)
+		}()
+
+		for chunk, err := range etts.pool.Synthesize(ctx, text, args, format) {
+			switch {
+			case err != nil:
+				turnErr = err
+
+				// request was not sent, so rate limit token is not spent
+				if etts.limiter != nil && errors.Is(err, ErrTooManyConnections) {
+					etts.limiter.Return()
+				}
+			case chunk.ChunkType == tts.ChunkTypeEnd:
+				done = true
+			case chunk.ChunkType == tts.ChunkTypeUnknown:
+				// already passed to handler set with WithUnknownMessageHandler()
+				continue
+			}
+
+			if !yield(chunk, err) {
+				return
+			}
+		}
+	}
+}
+
+// report passes result of request to circuit breaker and adaptive concurrency limit
+func (etts *EdgeTTS) report(probe bool, done bool, err error) {
+	failure, throttled := tts.ClassifyError(err)
+
+	if etts.adaptive != nil {
+		switch {
+		case throttled:
+			etts.adaptive.Throttled()
+		case done:
+			etts.adaptive.Success()
+		}
+	}
+
+	if etts.breaker == nil {
+		return
+	}
+
+	switch {
+	case failure:
+		etts.breaker.Failure(probe)
+	case done:
+		etts.breaker.Success(probe)
+	default:
+		etts.breaker.Cancel(probe)
+	}
+}
+
+// wait waits for rate limit token
+func (etts *EdgeTTS) wait(ctx context.Context) error {
+	if etts.limiter == nil {
+		return nil
+	}
+
+	if etts.failFast {
+		if !etts.limiter.Allow() {
+			return ErrRateLimited
+		}
+
+		return nil
+	}
+
+	return etts.limiter.Wait(ctx)
+}
+
+// acquireConnection waits for free connection slot. Slot is held by connection until it is closed,
+// including time when connection is idle
+func (etts *EdgeTTS) acquireConnection(ctx context.Context) error {
+	if etts.failFast {
+		if !etts.connections.TryAcquire() {
+			return ErrTooManyConnections
+		}
+
+		return nil
+	}
+
+	return etts.connections.Acquire(ctx)
+}
@@ -0,0 +1,45 @@
+package edgetts
+
+import (
+	"errors"
+	"testing"
+)
+
+func Test_WithMaxConnectionsCountsWarm(t *testing.T) {
+	server := newEdgeServer(t)
+	etts := newTestEdgeTTS(server, WithMaxConnections(1), WithFailFastLimits())
+	defer etts.Close()
+
+	if err := etts.Warmup(t.Context(), OutputFormatRaw24000); err != nil {
+		t.Fatalf("expected error to be 'nil', but got '%v'", err)
+	}
+
+	if err := etts.Warmup(t.Context(), OutputFormatRaw24000); !errors.Is(err, ErrTooManyConnections) {
+		t.Errorf("expected error to be '%v', but got '%v'", ErrTooManyConnections, err)
+	}
+
+	// warm connection is used by synthesis
+	if _, err := etts.Speak("Hello").GetSound(t.Context(), OutputFormatRaw24000); err != nil {
+		t.Errorf("expected error to be 'nil', but got '%v'", err)
+	}
+}
+
+func Test_FailFastReturnsRateToken(t *testing.T) {
+	server := newEdgeServer(t)
+
+	// the only token is never refilled during test
+	etts := newTestEdgeTTS(server, WithRateLimit(0.001, 1), WithMaxConnections(1), WithFailFastLimits())
+	defer etts.Close()
+
+	etts.connections.TryAcquire()
+
+	if _, err := etts.Speak("Hello").GetSound(t.Context(), OutputFormatRaw24000); !errors.Is(err, ErrTooManyConnections) {
+		t.Errorf("expected error to be '%v', but got '%v'", ErrTooManyConnections, err)
+	}
+
+	etts.connections.Release()
+
+	if _, err := etts.Speak("Hello").GetSound(t.Context(), OutputFormatRaw24000); err != nil {
+		t.Errorf("expected error to be 'nil', but got '%v'", err)
+	}
+}
@@ -2,6 +2,7 @@ package edgetts
 
 import (
 	"context"
+	"io"
 
 	"github.com/kolonist/edgetts/internal/voices"
 )
@@ -9,6 +10,35 @@ import (
 // Voice description
 type Voice = voices.Voice
 
+// Gender of speaker
+type Gender = voices.Gender
+
+// Speaker gender possible values
+const (
+	GenderUnknown = voices.GenderUnknown
+	GenderMale    = voices.GenderMale
+	GenderFemale  = voices.GenderFemale
+	GenderNeutral = voices.GenderNeutral
+)
+
+// Status of voice availability
+type Status = voices.Status
+
+// Voice status possible values
+const (
+	// status not specified
+	StatusUnknown = voices.StatusUnknown
+
+	// General Availability
+	StatusGA = voices.StatusGA
+
+	// voice is in preview and can be changed or removed
+	StatusPreview = voices.StatusPreview
+
+	// voice is going to be removed
+	StatusDeprecated = voices.StatusDeprecated
+)
+
 // ListVoices gets list of all available voices to use in speech generation.
 //
 // Parameters:
@@ -21,3 +51,65 @@ type Voice = voices.Voice
 func ListVoices(ctx context.Context) ([]Voice, error) {
 	return voices.ListVoices(ctx)
 }
+
+// VoiceCatalogDiff contains voices added, removed and changed between two voice catalogs
+type VoiceCatalogDiff = voices.CatalogDiff
+
+// VoiceChange describes voice which exists in both catalogs but has different fields
+type VoiceChange = voices.VoiceChange
+
+// DiffVoices compares two voice catalogs, e.g. cached one against live one.
+//
+// Parameters:
+//
+//	oldVoices - previous voice catalog
+//	newVoices - current voice catalog
+//
+// Returns:
+//
+//	added, removed and changed voices matched by ShortName
+func DiffVoices(oldVoices []Voice, newVoices []Voice) VoiceCatalogDiff {
+	return voices.DiffVoices(oldVoices, newVoices)
+}
+
+// ReadVoices reads voice catalog snapshot in JSON format.
+//
+// Parameters:
+//
+//	r - reader with JSON array of voices, e.g. file written by WriteVoices()
+//
+// Returns:
+//
+//	slice of voices
+//	error if JSON is malformed
+func ReadVoices(r io.Reader) ([]Voice, error) {
+	return voices.ReadVoices(r)
+}
+
+// WriteVoices writes voice catalog snapshot in JSON format.
+//
+// Parameters:
+//
+//	w - writer to write JSON to
+//	voiceList - voice catalog
+//
+// Returns:
+//
+//	error if write failed
+func WriteVoices(w io.Writer, voiceList []Voice) error {
+	return voices.WriteVoices(w, voiceList)
+}
+
+// ParseLocale splits locale to language and region, dropping any variant.
+//
+// Parameters:
+//
+//	locale - locale like "en-US" or "zh-CN-guangxi"
+//
+// Returns:
+//
+//	language, e.g. "en" or "zh"
+//	region, e.g. "US" or "CN"
+func ParseLocale(locale string) (string, string) {
+	return voices.ParseLocale(locale)
+}